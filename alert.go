@@ -0,0 +1,56 @@
+package smpp
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fkgi/teldata"
+)
+
+type AlertNotificationPDU struct {
+	SrcTON   teldata.NatureOfAddress `json:"src_ton,omitempty"`
+	SrcNPI   teldata.NumberingPlan   `json:"src_npi,omitempty"`
+	SrcAddr  string                  `json:"src_addr,omitempty"`
+	EsmeTON  teldata.NatureOfAddress `json:"esme_ton,omitempty"`
+	EsmeNPI  teldata.NumberingPlan   `json:"esme_npi,omitempty"`
+	EsmeAddr string                  `json:"esme_addr,omitempty"`
+
+	Param OptionalParameters `json:"options,omitempty"`
+}
+
+func (d *AlertNotificationPDU) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "source_addr_ton:", d.SrcTON)
+	fmt.Fprintln(buf, Indent, "source_addr_npi:", d.SrcNPI)
+	fmt.Fprintln(buf, Indent, "source_addr    :", d.SrcAddr)
+	fmt.Fprintln(buf, Indent, "esme_addr_ton  :", d.EsmeTON)
+	fmt.Fprintln(buf, Indent, "esme_addr_npi  :", d.EsmeNPI)
+	fmt.Fprintln(buf, Indent, "esme_addr      :", d.EsmeAddr)
+	fmt.Fprint(buf, d.Param)
+	return buf.String()
+}
+
+func (*AlertNotificationPDU) CommandID() CommandID { return AlertNotification }
+
+func (d *AlertNotificationPDU) Marshal(v byte) []byte {
+	w := new(bytes.Buffer)
+	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
+	writeAddr(d.EsmeTON, d.EsmeNPI, d.EsmeAddr, w)
+	if v >= 0x34 {
+		d.Param.writeTo(w)
+	}
+	return w.Bytes()
+}
+
+func (d *AlertNotificationPDU) Unmarshal(data []byte) (e error) {
+	buf := bytes.NewBuffer(data)
+	if d.SrcTON, d.SrcNPI, d.SrcAddr, e = readAddr(buf); e != nil {
+	} else if d.EsmeTON, d.EsmeNPI, d.EsmeAddr, e = readAddr(buf); e != nil {
+	} else {
+		d.Param = OptionalParameters{}
+		e = d.Param.readFrom(buf)
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package smpp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSendAlert(t *testing.T) {
+	alerts := make(chan *AlertNotificationPDU, 1)
+	smsc := &Bind{Config: testConfig(okHandler)}
+	conf := testConfig(okHandler)
+	conf.AlertNotify = func(_ BindInfo, p *AlertNotificationPDU) { alerts <- p }
+	esme := &Bind{Config: conf}
+	pipe(t, smsc, esme)
+
+	if e := smsc.SendAlert(&AlertNotificationPDU{SrcAddr: "123"}); e != nil {
+		t.Fatal(e)
+	}
+	select {
+	case p := <-alerts:
+		if p.SrcAddr != "123" {
+			t.Fatal(p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("alert_notification is not received")
+	}
+	if e := esme.SendAlert(&AlertNotificationPDU{}); e != ErrIncorrectBindState {
+		t.Fatal(e)
+	}
+
+	esme.Close()
+	waitClosed(t, smsc, esme)
+	if e := smsc.SendAlert(&AlertNotificationPDU{}); e != ErrClosed {
+		t.Fatal(e)
+	}
+}
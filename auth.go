@@ -0,0 +1,54 @@
+package smpp
+
+import (
+	"crypto/subtle"
+	"net"
+)
+
+// Authenticator verifies bind request from ESME.
+// Bind is rejected with returned status if it is not StatOK.
+var Authenticator func(BindInfo, net.Addr) StatusCode = nil
+
+type Credential struct {
+	Password   string
+	SystemType string
+	Networks   []*net.IPNet
+}
+
+// CredentialTable is Authenticator with credentials of each system_id.
+type CredentialTable map[string]Credential
+
+func (t CredentialTable) Authenticate(i BindInfo, a net.Addr) StatusCode {
+	c, ok := t[i.PeerID]
+	if !ok {
+		return StatInvSysID
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Password), []byte(i.Password)) != 1 {
+		return StatInvPaswd
+	}
+	if c.SystemType != "" && c.SystemType != i.SystemType {
+		return StatInvSysTyp
+	}
+	if len(c.Networks) == 0 {
+		return StatOK
+	}
+
+	var ip net.IP
+	switch a := a.(type) {
+	case nil:
+	case *net.TCPAddr:
+		if a != nil {
+			ip = a.IP
+		}
+	default:
+		if h, _, e := net.SplitHostPort(a.String()); e == nil {
+			ip = net.ParseIP(h)
+		}
+	}
+	for _, n := range c.Networks {
+		if ip != nil && n.Contains(ip) {
+			return StatOK
+		}
+	}
+	return StatBindFail
+}
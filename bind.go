@@ -2,13 +2,16 @@ package smpp
 
 import (
 	"bufio"
-	"errors"
+	"context"
 	"net"
-	"time"
+	"sync"
+	"sync/atomic"
 
 	"github.com/fkgi/teldata"
 )
 
+// bindtype is type of bind that ESME requested,
+// it is same value in both ESME and SMSC side.
 type bindtype int
 
 const (
@@ -31,8 +34,28 @@ func (t bindtype) String() string {
 	}
 }
 
+type bindRole int
+
+const (
+	NilRole bindRole = iota
+	ESMERole
+	SMSCRole
+)
+
+func (r bindRole) String() string {
+	switch r {
+	case ESMERole:
+		return "ESME"
+	case SMSCRole:
+		return "SMSC"
+	default:
+		return "undefined"
+	}
+}
+
 type BindInfo struct {
 	BindType bindtype
+	Role     bindRole
 	PeerID   string
 
 	Password      string
@@ -46,11 +69,25 @@ type BindInfo struct {
 
 type Bind struct {
 	BindInfo
+	Config *Config
+
+	conf     *Config
 	con      net.Conn
 	ver      byte
 	eventQ   chan message
-	reqStack map[uint32]chan message
+	rxQ      chan message
+	active   atomic.Bool
+	done     chan struct{}
+	window   chan struct{}
+	inflight atomic.Int32
+	rxLimit  *RateLimit
+	txLimit  *RateLimit
 	sequence chan uint32
+
+	pmu     sync.Mutex
+	pending map[Request]struct{}
+	closing bool
+	drained chan struct{}
 }
 
 func (b *Bind) nextSequence() uint32 {
@@ -63,44 +100,122 @@ func (b *Bind) nextSequence() uint32 {
 	return ret
 }
 
-func (b *Bind) ListenAndServe(c net.Conn) (e error) {
+func (b *Bind) init(c net.Conn) *bufio.ReadWriter {
+	b.conf = b.Config.resolve()
 	b.con = c
 	b.sequence = make(chan uint32, 1)
 	b.sequence <- 1
-	b.ver = 0x34
+	b.ver = b.conf.InterfaceVersion
+	return bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c))
+}
+
+func (b *Bind) ListenAndServe(c net.Conn) (e error) {
+	buf := b.init(c)
+	defer c.Close()
+
+	var msg message
+	if msg, e = b.readPDU(buf); e != nil {
+		return
+	}
+	return b.accept(buf, msg)
+}
 
-	buf := bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c))
+// AcceptOutbindAndServe waits outbind from SMSC and binds as receiver.
+// The system_id and password of the outbind are verified by Authenticator,
+// the connection is closed without bind if it is rejected.
+func (b *Bind) AcceptOutbindAndServe(c net.Conn) (e error) {
+	buf := b.init(c)
 	defer c.Close()
 
 	var msg message
-	if msg, e = readPDU(buf); e != nil {
+	if msg, e = b.readPDU(buf); e != nil {
+		return
+	}
+	if msg.id != Outbind {
+		b.writePDU(buf, message{
+			id:   GenericNack,
+			stat: StatInvCmdID,
+			seq:  msg.seq})
+		e = ErrUnexpectedRequest
+		return
+	}
+	req := outbindReq{}
+	if e = req.Unmarshal(msg.body); e != nil {
 		return
 	}
 
+	info := b.BindInfo
+	info.Role = ESMERole
+	info.BindType = RxBind
+	info.PeerID = req.SystemID
+	info.Password = req.Password
+	info.SystemType = ""
+	if b.conf.Authenticator != nil {
+		if stat := b.conf.Authenticator(info, b.con.RemoteAddr()); stat != StatOK {
+			e = StatusError{Command: Outbind, Status: stat}
+			return
+		}
+	}
+	b.PeerID = req.SystemID
+	b.BindType = RxBind
+	return b.dial(buf)
+}
+
+func (b *Bind) OutbindAndServe(c net.Conn) (e error) {
+	buf := b.init(c)
+	defer c.Close()
+
+	req := outbindReq{
+		SystemID: b.conf.ID,
+		Password: b.Password}
+	if e = b.writePDU(buf, message{
+		id:   req.CommandID(),
+		seq:  b.nextSequence(),
+		body: req.Marshal(b.ver)}); e != nil {
+		return
+	}
+
+	var msg message
+	if msg, e = b.readPDU(buf); e != nil {
+		return
+	}
+	if msg.id != BindReceiver {
+		b.writePDU(buf, message{
+			id:   GenericNack,
+			stat: StatInvCmdID,
+			seq:  msg.seq})
+		e = ErrUnexpectedRequest
+		return
+	}
+	return b.accept(buf, msg)
+}
+
+func (b *Bind) accept(buf *bufio.ReadWriter, msg message) (e error) {
 	switch msg.id {
 	case BindReceiver:
-		b.BindType = TxBind
-	case BindTransmitter:
 		b.BindType = RxBind
+	case BindTransmitter:
+		b.BindType = TxBind
 	case BindTransceiver:
 		b.BindType = TRxBind
 	default:
-		writePDU(buf, message{
+		b.writePDU(buf, message{
 			id:   GenericNack,
 			stat: StatInvCmdID,
 			seq:  msg.seq})
-		e = errors.New("invalid request for binding")
+		e = ErrUnexpectedRequest
 		return
 	}
 
+	b.Role = SMSCRole
 	req := bindReq{cmd: msg.id}
 	res := bindRes{
 		cmd:      msg.id | GenericNack,
-		SystemID: ID,
+		SystemID: b.conf.ID,
 		Version:  b.ver}
 
 	if e = req.Unmarshal(msg.body); e != nil {
-		writePDU(buf, message{
+		b.writePDU(buf, message{
 			id:   res.CommandID(),
 			stat: StatBindFail,
 			seq:  msg.seq})
@@ -114,11 +229,22 @@ func (b *Bind) ListenAndServe(c net.Conn) (e error) {
 	b.NumberingPlan = req.AddrNPI
 	b.AddressRange = req.AddrRange
 
+	if b.conf.Authenticator != nil {
+		if stat := b.conf.Authenticator(b.BindInfo, b.con.RemoteAddr()); stat != StatOK {
+			b.writePDU(buf, message{
+				id:   res.CommandID(),
+				stat: stat,
+				seq:  msg.seq})
+			e = StatusError{Command: msg.id, Status: stat}
+			return
+		}
+	}
+
 	if req.Version < b.ver {
 		b.ver = req.Version
 	}
 
-	if e = writePDU(buf, message{
+	if e = b.writePDU(buf, message{
 		id:   res.CommandID(),
 		stat: StatOK,
 		seq:  msg.seq,
@@ -126,24 +252,24 @@ func (b *Bind) ListenAndServe(c net.Conn) (e error) {
 		return
 	}
 
-	if BoundNotify != nil {
-		BoundNotify(b.BindInfo, b.con.RemoteAddr())
+	if b.conf.BoundNotify != nil {
+		b.conf.BoundNotify(b.BindInfo, b.con.RemoteAddr())
 	}
 
 	return b.serve(buf)
 }
 
 func (b *Bind) DialAndServe(c net.Conn) (e error) {
-	b.con = c
-	b.sequence = make(chan uint32, 1)
-	b.sequence <- 1
-	b.ver = 0x34
-
-	buf := bufio.NewReadWriter(bufio.NewReader(c), bufio.NewWriter(c))
+	buf := b.init(c)
 	defer c.Close()
 
+	return b.dial(buf)
+}
+
+func (b *Bind) dial(buf *bufio.ReadWriter) (e error) {
+	b.Role = ESMERole
 	req := bindReq{
-		SystemID:   ID,
+		SystemID:   b.conf.ID,
 		Password:   b.Password,
 		SystemType: b.SystemType,
 		Version:    b.ver,
@@ -158,32 +284,32 @@ func (b *Bind) DialAndServe(c net.Conn) (e error) {
 	case TRxBind:
 		req.cmd = BindTransceiver
 	default:
-		return errors.New("invalid bind type")
+		return ErrInvalidBindType
 	}
 	seq := b.nextSequence()
 
-	if e = writePDU(buf, message{
+	if e = b.writePDU(buf, message{
 		id:   req.CommandID(),
 		seq:  seq,
 		body: req.Marshal(b.ver)}); e != nil {
 		return
 	}
 
-	msg, e := readPDU(buf)
+	msg, e := b.readPDU(buf)
 	if e != nil {
 		return
 	}
 
 	if msg.id != req.cmd|GenericNack {
-		e = errors.New("invalid response")
+		e = ErrUnexpectedResponse
 		return
 	}
 	if msg.stat != 0 {
-		e = errors.New("error response")
+		e = StatusError{Command: req.cmd, Status: msg.stat}
 		return
 	}
 	if seq != msg.seq {
-		e = errors.New("invalid sequence")
+		e = ErrInvalidSequence
 		return
 	}
 
@@ -196,73 +322,266 @@ func (b *Bind) DialAndServe(c net.Conn) (e error) {
 		b.ver = res.Version
 	}
 
-	if BoundNotify != nil {
-		BoundNotify(b.BindInfo, b.con.RemoteAddr())
+	if b.conf.BoundNotify != nil {
+		b.conf.BoundNotify(b.BindInfo, b.con.RemoteAddr())
 	}
 
 	return b.serve(buf)
 }
 
 func (b *Bind) Close() {
-	if b.reqStack == nil {
+	if !b.IsActive() {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), b.conf.Expire)
+	defer cancel()
+	<-b.call(ctx, Unbind, nil)
+
+	b.con.Close()
+}
+
+func ctxError(ctx context.Context) error {
+	if e := ctx.Err(); e == context.Canceled {
+		return e
+	}
+	return ErrTimeout
+}
+
+// call sends request message and returns channel for the answer.
+// internalFailure is answered if ctx is done before the answer,
+// closeConnection is answered if the bind is closed.
+func (b *Bind) call(ctx context.Context, id CommandID, body []byte) <-chan message {
+	return b.callSeq(ctx, id, b.nextSequence(), body)
+}
+
+func (b *Bind) callSeq(ctx context.Context, id CommandID, seq uint32, body []byte) <-chan message {
+	ans := make(chan message, 1)
 	msg := message{
-		id:       Unbind,
-		seq:      b.nextSequence(),
+		id:       id,
+		seq:      seq,
+		body:     body,
 		callback: make(chan message)}
-	b.eventQ <- msg
+	select {
+	case b.eventQ <- msg:
+	case <-b.done:
+		ans <- message{id: closeConnection, seq: msg.seq}
+		return ans
+	}
 
-	wt := time.AfterFunc(Expire, func() {
-		b.eventQ <- message{
+	go func() {
+		select {
+		case a := <-msg.callback:
+			ans <- a
+			return
+		case <-b.done:
+			ans <- message{id: closeConnection, seq: msg.seq}
+			return
+		case <-ctx.Done():
+		}
+
+		select {
+		case b.eventQ <- message{
 			id:   internalFailure,
 			stat: 0xFFFFFFFF,
-			seq:  msg.seq}
-	})
-	msg = <-msg.callback
-	wt.Stop()
+			seq:  msg.seq}:
+		case <-b.done:
+			ans <- message{id: closeConnection, seq: msg.seq}
+			return
+		}
+		select {
+		case a := <-msg.callback:
+			ans <- a
+		case <-b.done:
+			ans <- message{id: closeConnection, seq: msg.seq}
+		}
+	}()
+	return ans
+}
 
-	b.con.Close()
+func (b *Bind) checkRequest(r PDU) error {
+	if !b.IsActive() {
+		return ErrClosed
+	}
+	if !b.permits(Tx, r.CommandID()) {
+		return ErrIncorrectBindState
+	}
+	switch r.CommandID() {
+	case BroadcastSm, QueryBroadcastSm, CancelBroadcastSm:
+		if b.ver < 0x50 {
+			return ErrUnsupported
+		}
+	}
+	return nil
 }
 
-func (b *Bind) Send(r PDU) (s StatusCode, a PDU, e error) {
-	if b.reqStack == nil {
-		// s= StatInvBndSts
-		// a=&genericNack{}
-		e = errors.New("closed bind")
-		return
+type Response struct {
+	Status StatusCode
+	PDU    PDU
+	Err    error
+}
+
+// Send sends request PDU and wait the response until Expire.
+func (b *Bind) Send(r PDU) (StatusCode, PDU, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.config().Expire)
+	defer cancel()
+	return b.SendContext(ctx, r)
+}
+
+// SendContext sends request PDU and wait the response until ctx is done.
+// Expire is applied if ctx has no deadline.
+func (b *Bind) SendContext(ctx context.Context, r PDU) (StatusCode, PDU, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.config().Expire)
+		defer cancel()
 	}
+	a := <-b.SendAsync(ctx, r)
+	return a.Status, a.PDU, a.Err
+}
 
-	msg := message{
+// SendAsync sends request PDU and returns channel for the response.
+// Response is written to the channel when it is received or ctx is done.
+// SendAsync blocks while the window is full.
+func (b *Bind) SendAsync(ctx context.Context, r PDU) <-chan Response {
+	res := make(chan Response, 1)
+	if e := b.checkRequest(r); e != nil {
+		res <- Response{Err: e}
+		return res
+	}
+	body, e := b.marshal(r)
+	if e != nil {
+		res <- Response{Err: e}
+		return res
+	}
+	if e := b.txLimit.Wait(ctx); e != nil {
+		res <- Response{Err: e}
+		return res
+	}
+	if e := b.acquire(ctx); e != nil {
+		res <- Response{Err: e}
+		return res
+	}
+	req := Request{Direction: Tx, CommandID: r.CommandID(), Sequence: b.nextSequence()}
+	if !b.track(req) {
+		b.release()
+		res <- Response{Err: ErrClosed}
+		return res
+	}
+
+	ans := b.callSeq(ctx, req.CommandID, req.Sequence, body)
+	go func() {
+		msg := <-ans
+		b.release()
+		b.untrack(req)
+		a := Response{Status: msg.stat}
+		switch msg.id {
+		case QuerySmResp, SubmitSmResp, DeliverSmResp, ReplaceSmResp, CancelSmResp,
+			SubmitMultiResp, DataSmResp, BroadcastSmResp, QueryBroadcastSmResp,
+			CancelBroadcastSmResp, GenericNack:
+			a.PDU = MakePDUof(msg.id)
+			a.Err = b.unmarshal(a.PDU, msg.body)
+			if !b.conf.AdaptiveRate {
+			} else if msg.stat == StatThrottled {
+				b.txLimit.throttled(b.conf.ThrottlePause)
+			} else if msg.stat == StatOK {
+				b.txLimit.recovered()
+			}
+		case internalFailure:
+			a.Err = ctxError(ctx)
+		case closeConnection:
+			a.Err = ErrClosed
+		default:
+			a.Err = ErrUnexpectedResponse
+		}
+		res <- a
+	}()
+	return res
+}
+
+func (b *Bind) acquire(ctx context.Context) error {
+	if b.window == nil {
+	} else if b.conf.FailFast {
+		select {
+		case b.window <- struct{}{}:
+		default:
+			return ErrWindowFull
+		}
+	} else {
+		select {
+		case b.window <- struct{}{}:
+		case <-ctx.Done():
+			return ctxError(ctx)
+		case <-b.done:
+			return ErrClosed
+		}
+	}
+	b.inflight.Add(1)
+	return nil
+}
+
+func (b *Bind) release() {
+	b.inflight.Add(-1)
+	if b.window != nil {
+		<-b.window
+	}
+}
+
+// InFlight returns number of unacknowledged requests.
+func (b *Bind) InFlight() int {
+	return int(b.inflight.Load())
+}
+
+func (b *Bind) SendAlert(r *AlertNotificationPDU) error {
+	if !b.IsActive() {
+		return ErrClosed
+	}
+	if !b.permits(Tx, r.CommandID()) || b.isClosing() {
+		return ErrIncorrectBindState
+	}
+	body, e := b.marshal(r)
+	if e != nil {
+		return e
+	}
+
+	select {
+	case b.eventQ <- message{
 		id:       r.CommandID(),
 		seq:      b.nextSequence(),
-		body:     r.Marshal(b.ver),
-		callback: make(chan message)}
-	b.eventQ <- msg
-
-	wt := time.AfterFunc(Expire, func() {
-		b.eventQ <- message{
-			id:   internalFailure,
-			stat: 0xFFFFFFFF,
-			seq:  msg.seq}
-	})
-	msg = <-msg.callback
-	wt.Stop()
+		body:     body,
+		callback: dummyCallback}:
+		return nil
+	case <-b.done:
+		return ErrClosed
+	}
+}
 
-	s = msg.stat
-	switch msg.id {
-	case SubmitSmResp, DeliverSmResp, DataSmResp, GenericNack:
-		a = MakePDUof(msg.id)
-		e = a.Unmarshal(msg.body)
-	case internalFailure:
-		e = errors.New("request timeout")
+// permits check the command is allowed in current bind status.
+func (i BindInfo) permits(d Direction, c CommandID) bool {
+	toSMSC := (i.Role == ESMERole) == (d == Tx)
+	switch c {
+	case SubmitSm, SubmitMulti, QuerySm, ReplaceSm, CancelSm,
+		BroadcastSm, QueryBroadcastSm, CancelBroadcastSm:
+		if !toSMSC {
+			return false
+		}
+	case DeliverSm, AlertNotification:
+		if toSMSC {
+			return false
+		}
+	case DataSm:
 	default:
-		e = errors.New("unexpected response")
+		return true
+	}
+
+	if toSMSC {
+		return i.BindType == TxBind || i.BindType == TRxBind
 	}
-	return
+	return i.BindType == RxBind || i.BindType == TRxBind
 }
 
+// IsActive returns true while the bind is served.
+// Other fields of the bind are ready to use when it returns true.
 func (b *Bind) IsActive() bool {
-	return b.reqStack != nil
+	return b.active.Load()
 }
@@ -0,0 +1,184 @@
+package smpp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testConfig returns Config with short timers and handler h.
+func testConfig(h func(BindInfo, PDU) (StatusCode, PDU)) *Config {
+	return &Config{Expire: time.Second, KeepAlive: time.Minute, RequestHandler: h}
+}
+
+func okHandler(_ BindInfo, p PDU) (StatusCode, PDU) {
+	switch p.(type) {
+	case *SubmitSM:
+		return StatOK, &SubmitSM_resp{MessageID: "id"}
+	case *DeliverSM:
+		return StatOK, &DeliverSM_resp{}
+	}
+	return StatSysErr, nil
+}
+
+// waitActive waits until all binds are active.
+func waitActive(t *testing.T, binds ...*Bind) {
+	t.Helper()
+	for _, b := range binds {
+		for i := 0; !b.IsActive(); i++ {
+			if i == 200 {
+				t.Fatal("bind is not active")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+}
+
+// waitClosed waits until all binds are closed.
+func waitClosed(t *testing.T, binds ...*Bind) {
+	t.Helper()
+	for _, b := range binds {
+		for i := 0; b.IsActive(); i++ {
+			if i == 200 {
+				t.Fatal("bind is not closed")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+}
+
+// pipe serves smsc and esme binds that are connected with net.Pipe.
+func pipe(t *testing.T, smsc, esme *Bind) {
+	t.Helper()
+	if esme.BindType == NilBind {
+		esme.BindType = TRxBind
+	}
+	c1, c2 := net.Pipe()
+	go smsc.ListenAndServe(c1)
+	go esme.DialAndServe(c2)
+	waitActive(t, smsc, esme)
+	t.Cleanup(func() {
+		esme.Close()
+		smsc.Close()
+	})
+}
+
+// pipeListener is net.Listener that accepts net.Pipe connections.
+type pipeListener struct {
+	conns chan net.Conn
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newPipeListener() *pipeListener {
+	return &pipeListener{conns: make(chan net.Conn), done: make(chan struct{})}
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	c, _ := net.Pipe()
+	defer c.Close()
+	return c.LocalAddr()
+}
+
+// dial returns client side of new connection to the listener.
+func (l *pipeListener) dial() (net.Conn, error) {
+	c1, c2 := net.Pipe()
+	select {
+	case l.conns <- c1:
+		return c2, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func TestBindSession(t *testing.T) {
+	smsc := &Bind{Config: testConfig(okHandler)}
+	esme := &Bind{Config: testConfig(okHandler)}
+	pipe(t, smsc, esme)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s, res, e := esme.Send(&SubmitSM{})
+			if e != nil || s != StatOK || res.(*SubmitSM_resp).MessageID != "id" {
+				t.Error(s, res, e)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if s, _, e := smsc.Send(&DeliverSM{}); e != nil || s != StatOK {
+				t.Error(s, e)
+			}
+		}()
+	}
+	wg.Wait()
+
+	esme.Close()
+	waitClosed(t, smsc, esme)
+	if _, _, e := esme.Send(&SubmitSM{}); e != ErrClosed {
+		t.Fatal(e)
+	}
+}
+
+func TestBindWindow(t *testing.T) {
+	for _, failFast := range []bool{true, false} {
+		release := make(chan struct{})
+		smsc := &Bind{Config: testConfig(func(i BindInfo, p PDU) (StatusCode, PDU) {
+			<-release
+			return okHandler(i, p)
+		})}
+		conf := testConfig(okHandler)
+		conf.Window = 2
+		conf.FailFast = failFast
+		esme := &Bind{Config: conf}
+		pipe(t, smsc, esme)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if s, _, e := esme.Send(&SubmitSM{}); e != nil || s != StatOK {
+					t.Error(s, e)
+				}
+			}()
+		}
+		for i := 0; esme.InFlight() != 2; i++ {
+			if i == 200 {
+				t.Fatal(esme.InFlight())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, _, e := esme.SendContext(ctx, &SubmitSM{})
+		cancel()
+		if failFast && e != ErrWindowFull || !failFast && e != ErrTimeout {
+			t.Errorf("fail fast %v: %v", failFast, e)
+		}
+
+		close(release)
+		wg.Wait()
+		if n := esme.InFlight(); n != 0 {
+			t.Fatal(n, "requests in flight")
+		}
+	}
+}
@@ -92,6 +92,8 @@ func (d *bindRes) Unmarshal(data []byte) (e error) {
 	}
 	if v, ok := p[0x0210]; ok && len(v) == 1 {
 		d.Version = v[0]
+	} else {
+		d.Version = 0x33
 	}
 	return
 }
@@ -123,3 +125,33 @@ func (*enquireRes) CommandID() CommandID   { return EnquireLinkResp }
 func (*enquireRes) String() string         { return "" }
 func (*enquireRes) Marshal(byte) []byte    { return []byte{} }
 func (*enquireRes) Unmarshal([]byte) error { return nil }
+
+type outbindReq struct {
+	SystemID string `json:"system_id"`
+	Password string `json:"passsword"`
+}
+
+func (*outbindReq) CommandID() CommandID { return Outbind }
+
+func (d *outbindReq) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "system_id:", d.SystemID)
+	fmt.Fprint(buf, Indent, " passsword: ", d.Password)
+	return buf.String()
+}
+
+func (d *outbindReq) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.SystemID), w)
+	writeCString([]byte(d.Password), w)
+	return w.Bytes()
+}
+
+func (d *outbindReq) Unmarshal(data []byte) (e error) {
+	buf := bytes.NewBuffer(data)
+	if d.SystemID, e = readCString(buf); e == nil {
+		d.Password, e = readCString(buf)
+	}
+	return
+}
@@ -0,0 +1,260 @@
+package smpp
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fkgi/teldata"
+)
+
+func (p OptionalParameters) require(ids ...uint16) error {
+	for _, i := range ids {
+		if _, ok := p[i]; !ok {
+			return fmt.Errorf("%w: %s", ErrMissingParam, IdToHexString(i))
+		}
+	}
+	return nil
+}
+
+type BroadcastSM struct {
+	SvcType              string                  `json:"svc_type,omitempty"`
+	SrcTON               teldata.NatureOfAddress `json:"src_ton,omitempty"`
+	SrcNPI               teldata.NumberingPlan   `json:"src_npi,omitempty"`
+	SrcAddr              string                  `json:"src_addr,omitempty"`
+	MessageID            string                  `json:"id,omitempty"`
+	PriorityFlag         byte                    `json:"priority_flag"`
+	ScheduleDeliveryTime string                  `json:"schedule_delivery_time,omitempty"`
+	ValidityPeriod       string                  `json:"validity_period,omitempty"`
+	ReplaceIfPresentFlag bool                    `json:"replace_if_present_flag,omitempty"`
+	DataCoding           DataCoding              `json:"data_coding"`
+	SmDefaultMsgId       byte                    `json:"sm_default_sm_id,omitempty"`
+
+	Param OptionalParameters `json:"options,omitempty"`
+}
+
+func (d *BroadcastSM) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "service_type           :", d.SvcType)
+	fmt.Fprintln(buf, Indent, "source_addr_ton        :", d.SrcTON)
+	fmt.Fprintln(buf, Indent, "source_addr_npi        :", d.SrcNPI)
+	fmt.Fprintln(buf, Indent, "source_addr            :", d.SrcAddr)
+	fmt.Fprintln(buf, Indent, "message_id             :", d.MessageID)
+	fmt.Fprintln(buf, Indent, "priority_flag          :", d.PriorityFlag)
+	fmt.Fprintln(buf, Indent, "schedule_delivery_time :", d.ScheduleDeliveryTime)
+	fmt.Fprintln(buf, Indent, "validity_period        :", d.ValidityPeriod)
+	fmt.Fprintln(buf, Indent, "replace_if_present_flag:", d.ReplaceIfPresentFlag)
+	fmt.Fprintln(buf, Indent, "data_coding            :", d.DataCoding)
+	fmt.Fprintln(buf, Indent, "sm_default_msg_id      :", d.SmDefaultMsgId)
+	fmt.Fprint(buf, d.Param)
+	return buf.String()
+}
+
+func (*BroadcastSM) CommandID() CommandID { return BroadcastSm }
+
+func (d *BroadcastSM) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.SvcType), w)
+	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
+	writeCString([]byte(d.MessageID), w)
+	w.WriteByte(d.PriorityFlag)
+	writeCString([]byte(d.ScheduleDeliveryTime), w)
+	writeCString([]byte(d.ValidityPeriod), w)
+	writeBool(d.ReplaceIfPresentFlag, w)
+	d.DataCoding.writeTo(w)
+	w.WriteByte(d.SmDefaultMsgId)
+	d.Param.writeTo(w)
+	return w.Bytes()
+}
+
+func (d *BroadcastSM) Unmarshal(data []byte) (e error) {
+	buf := bytes.NewBuffer(data)
+	if d.SvcType, e = readCString(buf); e != nil {
+	} else if d.SrcTON, d.SrcNPI, d.SrcAddr, e = readAddr(buf); e != nil {
+	} else if d.MessageID, e = readCString(buf); e != nil {
+	} else if d.PriorityFlag, e = buf.ReadByte(); e != nil {
+	} else if d.ScheduleDeliveryTime, e = readCString(buf); e != nil {
+	} else if d.ValidityPeriod, e = readCString(buf); e != nil {
+	} else if d.ReplaceIfPresentFlag, e = readBool(buf); e != nil {
+	} else if e = d.DataCoding.readFrom(buf); e != nil {
+	} else if d.SmDefaultMsgId, e = buf.ReadByte(); e != nil {
+	} else {
+		d.Param = OptionalParameters{}
+		if e = d.Param.readFrom(buf); e == nil {
+			e = d.Param.require(
+				0x0606, // broadcast_area_identifier
+				0x0601, // broadcast_content_type
+				0x0604, // broadcast_rep_num
+				0x0605) // broadcast_frequency_interval
+		}
+	}
+	return
+}
+
+type BroadcastSM_resp struct {
+	MessageID string             `json:"id,omitempty"`
+	Param     OptionalParameters `json:"options,omitempty"`
+}
+
+func (d *BroadcastSM_resp) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "id:", d.MessageID)
+	fmt.Fprint(buf, d.Param)
+	return buf.String()
+}
+
+func (*BroadcastSM_resp) CommandID() CommandID { return BroadcastSmResp }
+
+func (d *BroadcastSM_resp) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.MessageID), w)
+	d.Param.writeTo(w)
+	return w.Bytes()
+}
+
+func (d *BroadcastSM_resp) Unmarshal(data []byte) (e error) {
+	if len(data) == 0 {
+		return
+	}
+	buf := bytes.NewBuffer(data)
+	if d.MessageID, e = readCString(buf); e == nil {
+		d.Param = OptionalParameters{}
+		e = d.Param.readFrom(buf)
+	}
+	return
+}
+
+type QueryBroadcastSM struct {
+	MessageID string                  `json:"id"`
+	SrcTON    teldata.NatureOfAddress `json:"src_ton,omitempty"`
+	SrcNPI    teldata.NumberingPlan   `json:"src_npi,omitempty"`
+	SrcAddr   string                  `json:"src_addr,omitempty"`
+
+	Param OptionalParameters `json:"options,omitempty"`
+}
+
+func (d *QueryBroadcastSM) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "message_id     :", d.MessageID)
+	fmt.Fprintln(buf, Indent, "source_addr_ton:", d.SrcTON)
+	fmt.Fprintln(buf, Indent, "source_addr_npi:", d.SrcNPI)
+	fmt.Fprintln(buf, Indent, "source_addr    :", d.SrcAddr)
+	fmt.Fprint(buf, d.Param)
+	return buf.String()
+}
+
+func (*QueryBroadcastSM) CommandID() CommandID { return QueryBroadcastSm }
+
+func (d *QueryBroadcastSM) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.MessageID), w)
+	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
+	d.Param.writeTo(w)
+	return w.Bytes()
+}
+
+func (d *QueryBroadcastSM) Unmarshal(data []byte) (e error) {
+	buf := bytes.NewBuffer(data)
+	if d.MessageID, e = readCString(buf); e != nil {
+	} else if d.SrcTON, d.SrcNPI, d.SrcAddr, e = readAddr(buf); e != nil {
+	} else {
+		d.Param = OptionalParameters{}
+		e = d.Param.readFrom(buf)
+	}
+	return
+}
+
+type QueryBroadcastSM_resp struct {
+	MessageID string             `json:"id"`
+	Param     OptionalParameters `json:"options,omitempty"`
+}
+
+func (d *QueryBroadcastSM_resp) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "id:", d.MessageID)
+	fmt.Fprint(buf, d.Param)
+	return buf.String()
+}
+
+func (*QueryBroadcastSM_resp) CommandID() CommandID { return QueryBroadcastSmResp }
+
+func (d *QueryBroadcastSM_resp) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.MessageID), w)
+	d.Param.writeTo(w)
+	return w.Bytes()
+}
+
+func (d *QueryBroadcastSM_resp) Unmarshal(data []byte) (e error) {
+	if len(data) == 0 {
+		return
+	}
+	buf := bytes.NewBuffer(data)
+	if d.MessageID, e = readCString(buf); e != nil {
+		return
+	}
+	d.Param = OptionalParameters{}
+	if e = d.Param.readFrom(buf); e == nil {
+		e = d.Param.require(
+			0x0427, // message_state
+			0x0606, // broadcast_area_identifier
+			0x0608) // broadcast_area_success
+	}
+	return
+}
+
+type CancelBroadcastSM struct {
+	SvcType   string                  `json:"svc_type,omitempty"`
+	MessageID string                  `json:"id,omitempty"`
+	SrcTON    teldata.NatureOfAddress `json:"src_ton,omitempty"`
+	SrcNPI    teldata.NumberingPlan   `json:"src_npi,omitempty"`
+	SrcAddr   string                  `json:"src_addr,omitempty"`
+
+	Param OptionalParameters `json:"options,omitempty"`
+}
+
+func (d *CancelBroadcastSM) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "service_type   :", d.SvcType)
+	fmt.Fprintln(buf, Indent, "message_id     :", d.MessageID)
+	fmt.Fprintln(buf, Indent, "source_addr_ton:", d.SrcTON)
+	fmt.Fprintln(buf, Indent, "source_addr_npi:", d.SrcNPI)
+	fmt.Fprintln(buf, Indent, "source_addr    :", d.SrcAddr)
+	fmt.Fprint(buf, d.Param)
+	return buf.String()
+}
+
+func (*CancelBroadcastSM) CommandID() CommandID { return CancelBroadcastSm }
+
+func (d *CancelBroadcastSM) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.SvcType), w)
+	writeCString([]byte(d.MessageID), w)
+	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
+	d.Param.writeTo(w)
+	return w.Bytes()
+}
+
+func (d *CancelBroadcastSM) Unmarshal(data []byte) (e error) {
+	buf := bytes.NewBuffer(data)
+	if d.SvcType, e = readCString(buf); e != nil {
+	} else if d.MessageID, e = readCString(buf); e != nil {
+	} else if d.SrcTON, d.SrcNPI, d.SrcAddr, e = readAddr(buf); e != nil {
+	} else {
+		d.Param = OptionalParameters{}
+		e = d.Param.readFrom(buf)
+	}
+	return
+}
+
+type CancelBroadcastSM_resp struct{}
+
+func (*CancelBroadcastSM_resp) String() string         { return "" }
+func (*CancelBroadcastSM_resp) CommandID() CommandID   { return CancelBroadcastSmResp }
+func (*CancelBroadcastSM_resp) Marshal(byte) []byte    { return []byte{} }
+func (*CancelBroadcastSM_resp) Unmarshal([]byte) error { return nil }
@@ -0,0 +1,63 @@
+package smpp
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fkgi/teldata"
+)
+
+type CancelSM struct {
+	SvcType   string                  `json:"svc_type,omitempty"`
+	MessageID string                  `json:"id,omitempty"`
+	SrcTON    teldata.NatureOfAddress `json:"src_ton,omitempty"`
+	SrcNPI    teldata.NumberingPlan   `json:"src_npi,omitempty"`
+	SrcAddr   string                  `json:"src_addr,omitempty"`
+	DstTON    teldata.NatureOfAddress `json:"dst_ton,omitempty"`
+	DstNPI    teldata.NumberingPlan   `json:"dst_npi,omitempty"`
+	DstAddr   string                  `json:"dst_addr,omitempty"`
+}
+
+func (d *CancelSM) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "service_type    :", d.SvcType)
+	fmt.Fprintln(buf, Indent, "message_id      :", d.MessageID)
+	fmt.Fprintln(buf, Indent, "source_addr_ton :", d.SrcTON)
+	fmt.Fprintln(buf, Indent, "source_addr_npi :", d.SrcNPI)
+	fmt.Fprintln(buf, Indent, "source_addr     :", d.SrcAddr)
+	fmt.Fprintln(buf, Indent, "dest_addr_ton   :", d.DstTON)
+	fmt.Fprintln(buf, Indent, "dest_addr_npi   :", d.DstNPI)
+	fmt.Fprint(buf, Indent, " destination_addr: ", d.DstAddr)
+	return buf.String()
+}
+
+func (*CancelSM) CommandID() CommandID { return CancelSm }
+
+func (d *CancelSM) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.SvcType), w)
+	writeCString([]byte(d.MessageID), w)
+	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
+	writeAddr(d.DstTON, d.DstNPI, d.DstAddr, w)
+	return w.Bytes()
+}
+
+func (d *CancelSM) Unmarshal(data []byte) (e error) {
+	buf := bytes.NewBuffer(data)
+	if d.SvcType, e = readCString(buf); e != nil {
+	} else if d.MessageID, e = readCString(buf); e != nil {
+	} else if d.SrcTON, d.SrcNPI, d.SrcAddr, e = readAddr(buf); e != nil {
+	} else {
+		d.DstTON, d.DstNPI, d.DstAddr, e = readAddr(buf)
+	}
+	return
+}
+
+type CancelSM_resp struct{}
+
+func (*CancelSM_resp) String() string         { return "" }
+func (*CancelSM_resp) CommandID() CommandID   { return CancelSmResp }
+func (*CancelSM_resp) Marshal(byte) []byte    { return []byte{} }
+func (*CancelSM_resp) Unmarshal([]byte) error { return nil }
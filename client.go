@@ -0,0 +1,228 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+type ClientState byte
+
+const (
+	Connecting ClientState = iota
+	Bound
+	Disconnected
+	Stopped
+)
+
+func (s ClientState) String() string {
+	switch s {
+	case Connecting:
+		return "connecting"
+	case Bound:
+		return "bound"
+	case Disconnected:
+		return "disconnected"
+	case Stopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+var (
+	MinBackoff = time.Second
+	MaxBackoff = time.Minute
+)
+
+// Client keeps a bind to the SMSC and reconnects it when it is closed.
+type Client struct {
+	BindInfo
+	Config *Config
+
+	// Dialer opens connection to the SMSC.
+	Dialer func(context.Context) (net.Conn, error)
+	// MinBackoff and MaxBackoff are range of the wait time before reconnect.
+	// Package-level variable is used if the value is 0.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// StateNotify is called when state of the client is changed.
+	// Stopped with error means the bind is rejected by authentication
+	// (StatInvPaswd, StatInvSysID or StatInvSysTyp) and the client is not retried.
+	StateNotify func(ClientState, error)
+
+	mu     sync.Mutex
+	bind   *Bind
+	con    net.Conn
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Start starts connecting to the SMSC in background.
+// ErrNoDialer is returned if Dialer is nil,
+// and ErrClientStarted is returned if the client is already started.
+func (c *Client) Start() error {
+	if c.Dialer == nil {
+		return ErrNoDialer
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done != nil {
+		return ErrClientStarted
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.run(ctx, c.done)
+	return nil
+}
+
+// Close stops reconnecting, closes current bind and waits the client is stopped.
+func (c *Client) Close() {
+	c.mu.Lock()
+	if c.done == nil {
+		c.mu.Unlock()
+		return
+	}
+	c.cancel()
+	b, con, done := c.bind, c.con, c.done
+	c.mu.Unlock()
+
+	if b != nil && b.IsActive() {
+		b.Close()
+	} else if con != nil {
+		con.Close()
+	}
+	<-done
+}
+
+func (c *Client) run(ctx context.Context, done chan struct{}) {
+	defer func() {
+		c.mu.Lock()
+		c.cancel()
+		c.done, c.cancel = nil, nil
+		c.mu.Unlock()
+		close(done)
+	}()
+
+	minb, maxb := c.MinBackoff, c.MaxBackoff
+	if minb == 0 {
+		minb = MinBackoff
+	}
+	if maxb == 0 {
+		maxb = MaxBackoff
+	}
+	if maxb < minb {
+		maxb = minb
+	}
+
+	backoff := minb
+	for {
+		c.notify(Connecting, nil)
+		con, e := c.Dialer(ctx)
+		if e == nil {
+			bound := false
+			b := &Bind{BindInfo: c.BindInfo}
+			conf := *c.Config.resolve()
+			notify := conf.BoundNotify
+			conf.BoundNotify = func(i BindInfo, a net.Addr) {
+				bound = true
+				c.notify(Bound, nil)
+				if notify != nil {
+					notify(i, a)
+				}
+			}
+			b.Config = &conf
+
+			c.mu.Lock()
+			if ctx.Err() != nil {
+				c.mu.Unlock()
+				con.Close()
+			} else {
+				c.bind, c.con = b, con
+				c.mu.Unlock()
+
+				e = b.DialAndServe(con)
+
+				c.mu.Lock()
+				c.bind, c.con = nil, nil
+				c.mu.Unlock()
+			}
+			if bound {
+				backoff = minb
+			}
+		}
+
+		if ctx.Err() != nil {
+			c.notify(Stopped, nil)
+			return
+		}
+		c.notify(Disconnected, e)
+
+		var se StatusError
+		if errors.As(e, &se) {
+			switch se.Status {
+			case StatInvPaswd, StatInvSysID, StatInvSysTyp:
+				c.notify(Stopped, e)
+				return
+			}
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			c.notify(Stopped, nil)
+			return
+		case <-t.C:
+		}
+		if backoff *= 2; backoff > maxb {
+			backoff = maxb
+		}
+	}
+}
+
+func (c *Client) notify(s ClientState, e error) {
+	if c.StateNotify != nil {
+		c.StateNotify(s, e)
+	}
+}
+
+// Bind returns current bind, or nil if no connection is available.
+func (c *Client) Bind() *Bind {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bind
+}
+
+func (c *Client) IsActive() bool {
+	if b := c.Bind(); b != nil {
+		return b.IsActive()
+	}
+	return false
+}
+
+func (c *Client) InFlight() int {
+	if b := c.Bind(); b != nil {
+		return b.InFlight()
+	}
+	return 0
+}
+
+func (c *Client) Send(r PDU) (StatusCode, PDU, error) {
+	if b := c.Bind(); b != nil {
+		return b.Send(r)
+	}
+	return StatSysErr, nil, ErrNoBind
+}
+
+func (c *Client) SendContext(ctx context.Context, r PDU) (StatusCode, PDU, error) {
+	if b := c.Bind(); b != nil {
+		return b.SendContext(ctx, r)
+	}
+	return StatSysErr, nil, ErrNoBind
+}
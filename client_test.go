@@ -0,0 +1,170 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientStart(t *testing.T) {
+	c := &Client{}
+	if e := c.Start(); e != ErrNoDialer {
+		t.Fatal(e)
+	}
+	c.Dialer = func(ctx context.Context) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if e := c.Start(); e != nil {
+		t.Fatal(e)
+	}
+	if e := c.Start(); e != ErrClientStarted {
+		t.Fatal(e)
+	}
+	c.Close()
+	if e := c.Start(); e != nil {
+		t.Fatal("not restarted after Close:", e)
+	}
+	c.Close()
+}
+
+func TestClientReconnect(t *testing.T) {
+	l := newPipeListener()
+	srv := &Server{Config: testConfig(okHandler)}
+	go srv.Serve(l)
+	defer srv.Shutdown(context.Background())
+
+	mu := sync.Mutex{}
+	states := []ClientState{}
+	c := &Client{
+		BindInfo:   BindInfo{BindType: TRxBind},
+		Config:     testConfig(okHandler),
+		MinBackoff: 10 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+		Dialer:     func(context.Context) (net.Conn, error) { return l.dial() },
+		StateNotify: func(s ClientState, _ error) {
+			mu.Lock()
+			states = append(states, s)
+			mu.Unlock()
+		}}
+	c.Start()
+	waitClient(t, c)
+	if s, _, e := c.Send(&SubmitSM{}); e != nil || s != StatOK {
+		t.Fatal(s, e)
+	}
+
+	// drop the bind from the server side
+	for _, b := range srv.Binds() {
+		b.Close()
+	}
+	time.Sleep(50 * time.Millisecond)
+	waitClient(t, c)
+	if s, _, e := c.Send(&SubmitSM{}); e != nil || s != StatOK {
+		t.Fatal(s, e)
+	}
+
+	c.Close()
+	if c.IsActive() {
+		t.Fatal("active after Close")
+	}
+	if _, _, e := c.Send(&SubmitSM{}); e != ErrNoBind {
+		t.Fatal(e)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []ClientState{Connecting, Bound, Disconnected, Connecting, Bound, Stopped}
+	if len(states) != len(want) {
+		t.Fatal(states)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatal(states)
+		}
+	}
+}
+
+func TestClientBackoff(t *testing.T) {
+	mu := sync.Mutex{}
+	dialed := []time.Time{}
+	c := &Client{
+		MinBackoff: 20 * time.Millisecond,
+		MaxBackoff: 80 * time.Millisecond,
+		Dialer: func(context.Context) (net.Conn, error) {
+			mu.Lock()
+			dialed = append(dialed, time.Now())
+			mu.Unlock()
+			return nil, errors.New("refused")
+		}}
+	c.Start()
+	time.Sleep(500 * time.Millisecond)
+	c.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) < 4 {
+		t.Fatal(len(dialed), "attempts")
+	}
+	backoff := c.MinBackoff
+	for i := 1; i < len(dialed); i++ {
+		// wait is between half and full of backoff
+		if d := dialed[i].Sub(dialed[i-1]); d < backoff/2 || d > backoff+50*time.Millisecond {
+			t.Errorf("attempt %d after %s, backoff %s", i, d, backoff)
+		}
+		if backoff *= 2; backoff > c.MaxBackoff {
+			backoff = c.MaxBackoff
+		}
+	}
+}
+
+func TestClientAuthFailure(t *testing.T) {
+	l := newPipeListener()
+	conf := testConfig(okHandler)
+	conf.Authenticator = func(BindInfo, net.Addr) StatusCode { return StatInvPaswd }
+	srv := &Server{Config: conf}
+	go srv.Serve(l)
+	defer srv.Shutdown(context.Background())
+
+	stopped := make(chan error, 2)
+	dials := 0
+	c := &Client{
+		BindInfo:   BindInfo{BindType: TRxBind},
+		Config:     testConfig(okHandler),
+		MinBackoff: time.Millisecond,
+		Dialer: func(context.Context) (net.Conn, error) {
+			dials++
+			return l.dial()
+		},
+		StateNotify: func(s ClientState, e error) {
+			if s == Stopped {
+				stopped <- e
+			}
+		}}
+	c.Start()
+	select {
+	case e := <-stopped:
+		if !errors.Is(e, StatusError{Status: StatInvPaswd}) {
+			t.Fatal(e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("client is not stopped")
+	}
+	c.Close()
+	if dials != 1 {
+		t.Fatal(dials, "dials")
+	}
+}
+
+// waitClient waits until the client is bound.
+func waitClient(t *testing.T, c *Client) {
+	t.Helper()
+	for i := 0; !c.IsActive(); i++ {
+		if i == 200 {
+			t.Fatal("client is not bound")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
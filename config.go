@@ -0,0 +1,121 @@
+package smpp
+
+import (
+	"net"
+	"time"
+)
+
+// Config is configuration of each Bind.
+// Package-level variable is used for the field that has zero value,
+// it is resolved when the bind is started.
+type Config struct {
+	ID               string
+	InterfaceVersion byte
+	KeepAlive        time.Duration
+	Expire           time.Duration
+
+	// Window is max number of unacknowledged Tx requests, 0 is unlimited.
+	// FailFast makes Send fail with ErrWindowFull when window is full.
+	Window   int
+	FailFast bool
+
+	// RequestHandler and AsyncHandler handle Rx requests.
+	// Package-level handlers are used only if both are nil.
+	RequestHandler func(BindInfo, PDU) (StatusCode, PDU)
+	// AsyncHandler is used instead of RequestHandler if it is not nil.
+	// StatSysErr is answered if no response is sent before ResponseTimeout.
+	AsyncHandler    func(BindInfo, PDU, *Responder)
+	ResponseTimeout time.Duration
+	Authenticator   func(BindInfo, net.Addr) StatusCode
+	BoundNotify     func(BindInfo, net.Addr)
+	UnboundNotify   func(BindInfo, net.Addr)
+	AlertNotify     func(BindInfo, *AlertNotificationPDU)
+	TraceMessage    func(Direction, CommandID, StatusCode, uint32, []byte, error)
+
+	DefaultAlphabetIsGSM *bool
+
+	// Workers and QueueSize are for handling Rx requests.
+	// Ordered handles Rx requests one by one in received order.
+	Workers   int
+	QueueSize int
+	Ordered   bool
+
+	// InboundRate and InboundBurst are rate limit of Rx requests for each bind,
+	// 0 is unlimited.
+	InboundRate  float64
+	InboundBurst int
+	// SystemThrottle is rate limit of Rx requests for each system_id.
+	SystemThrottle *Throttle
+
+	// OutboundRate and OutboundBurst are rate limit of Tx requests, 0 is unlimited.
+	// Tx requests wait for the token.
+	OutboundRate  float64
+	OutboundBurst int
+	// AdaptiveRate halves outbound rate and pauses Tx requests for ThrottlePause
+	// when StatThrottled is answered, then the rate is recovered gradually.
+	AdaptiveRate  bool
+	ThrottlePause time.Duration
+}
+
+func (c *Config) resolve() *Config {
+	r := Config{}
+	if c != nil {
+		r = *c
+	}
+	if r.ID == "" {
+		r.ID = ID
+	}
+	if r.InterfaceVersion == 0 {
+		r.InterfaceVersion = InterfaceVersion
+	}
+	if r.KeepAlive == 0 {
+		r.KeepAlive = KeepAlive
+	}
+	if r.Expire == 0 {
+		r.Expire = Expire
+	}
+	if r.RequestHandler == nil && r.AsyncHandler == nil {
+		r.RequestHandler = RequestHandler
+		r.AsyncHandler = AsyncHandler
+	}
+	if r.ResponseTimeout == 0 {
+		r.ResponseTimeout = r.Expire
+	}
+	if r.Authenticator == nil {
+		r.Authenticator = Authenticator
+	}
+	if r.BoundNotify == nil {
+		r.BoundNotify = BoundNotify
+	}
+	if r.UnboundNotify == nil {
+		r.UnboundNotify = UnboundNotify
+	}
+	if r.AlertNotify == nil {
+		r.AlertNotify = AlertNotify
+	}
+	if r.TraceMessage == nil {
+		r.TraceMessage = TraceMessage
+	}
+	if r.Workers == 0 {
+		r.Workers = Workers
+	}
+	if r.QueueSize == 0 {
+		r.QueueSize = QueueSize
+	}
+	if r.AdaptiveRate && r.ThrottlePause == 0 {
+		r.ThrottlePause = time.Second
+	}
+	if r.DefaultAlphabetIsGSM == nil {
+		gsm := DefaultAlphabetIsGSM
+		r.DefaultAlphabetIsGSM = &gsm
+	}
+	return &r
+}
+
+// config returns effective configuration of the bind.
+func (b *Bind) config() *Config {
+	if b.IsActive() {
+		return b.conf
+	}
+	return b.Config.resolve()
+}
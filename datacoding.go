@@ -0,0 +1,299 @@
+package smpp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/transform"
+)
+
+// DataCoding is data_coding of SMPP 3.4.
+// Values other than SMPP specific 0x00-0x0F are GSM DCS of 3GPP TS 23.038.
+type DataCoding byte
+
+const (
+	CodingDefault   DataCoding = 0x00 // SMSC default alphabet
+	CodingIA5       DataCoding = 0x01 // IA5 (CCITT T.50)/ASCII
+	CodingOctet     DataCoding = 0x02 // Octet unspecified
+	CodingLatin1    DataCoding = 0x03 // Latin 1 (ISO-8859-1)
+	CodingBinary    DataCoding = 0x04 // Octet unspecified
+	CodingJIS       DataCoding = 0x05 // JIS (X 0208-1990)
+	CodingCyrillic  DataCoding = 0x06 // Cyrillic (ISO-8859-5)
+	CodingHebrew    DataCoding = 0x07 // Latin/Hebrew (ISO-8859-8)
+	CodingUCS2      DataCoding = 0x08 // UCS2 (ISO/IEC-10646)
+	CodingPictogram DataCoding = 0x09 // Pictogram Encoding
+	CodingISO2022JP DataCoding = 0x0A // ISO-2022-JP (Music Codes)
+	CodingExtKanji  DataCoding = 0x0D // Extended Kanji JIS (X 0212-1990)
+	CodingKSC5601   DataCoding = 0x0E // KS C 5601
+)
+
+func (c DataCoding) String() string {
+	switch c {
+	case CodingDefault:
+		return "default"
+	case CodingIA5:
+		return "ia5"
+	case CodingOctet:
+		return "octet"
+	case CodingLatin1:
+		return "latin1"
+	case CodingBinary:
+		return "binary"
+	case CodingJIS:
+		return "jis"
+	case CodingCyrillic:
+		return "cyrillic"
+	case CodingHebrew:
+		return "hebrew"
+	case CodingUCS2:
+		return "ucs2"
+	case CodingPictogram:
+		return "pictogram"
+	case CodingISO2022JP:
+		return "iso2022jp"
+	case CodingExtKanji:
+		return "extended_kanji"
+	case CodingKSC5601:
+		return "ksc5601"
+	}
+	s := fmt.Sprintf("0x%02x(", byte(c))
+	switch c.alphabet() {
+	case CodingDefault:
+		s += "gsm7"
+	case CodingUCS2:
+		s += "ucs2"
+	default:
+		s += "8bit"
+	}
+	if m, ok := c.Class(); ok {
+		s += fmt.Sprintf(", class %d", m)
+	}
+	if c.Compressed() {
+		s += ", compressed"
+	}
+	return s + ")"
+}
+
+// MarshalJSON returns name of the data_coding, or number if it has no name.
+func (c DataCoding) MarshalJSON() ([]byte, error) {
+	if c <= CodingKSC5601 && c != 0x0B && c != 0x0C {
+		return json.Marshal(c.String())
+	}
+	return json.Marshal(byte(c))
+}
+
+// UnmarshalJSON accepts number or name of the data_coding.
+func (c *DataCoding) UnmarshalJSON(b []byte) (e error) {
+	var n byte
+	if json.Unmarshal(b, &n) == nil {
+		*c = DataCoding(n)
+		return
+	}
+	s := ""
+	if e = json.Unmarshal(b, &s); e != nil {
+		return
+	}
+	switch s {
+	case "default":
+		*c = CodingDefault
+	case "ia5":
+		*c = CodingIA5
+	case "octet":
+		*c = CodingOctet
+	case "latin1":
+		*c = CodingLatin1
+	case "binary":
+		*c = CodingBinary
+	case "jis":
+		*c = CodingJIS
+	case "cyrillic":
+		*c = CodingCyrillic
+	case "hebrew":
+		*c = CodingHebrew
+	case "ucs2":
+		*c = CodingUCS2
+	case "pictogram":
+		*c = CodingPictogram
+	case "iso2022jp":
+		*c = CodingISO2022JP
+	case "extended_kanji":
+		*c = CodingExtKanji
+	case "ksc5601":
+		*c = CodingKSC5601
+	default:
+		e = errors.New("invalid Data Coding: " + s)
+	}
+	return
+}
+
+func (c DataCoding) writeTo(buf *bytes.Buffer) {
+	buf.WriteByte(byte(c))
+}
+
+func (c *DataCoding) readFrom(buf *bytes.Buffer) error {
+	b, e := buf.ReadByte()
+	if e == nil {
+		*c = DataCoding(b)
+	}
+	return e
+}
+
+// Class returns message class of GSM DCS.
+func (c DataCoding) Class() (byte, bool) {
+	switch {
+	case c < 0x10:
+	case c < 0x80 && c&0x10 == 0x10, c&0xf0 == 0xf0:
+		return byte(c & 0x03), true
+	}
+	return 0, false
+}
+
+// Compressed returns true if the text is compressed in GSM DCS.
+func (c DataCoding) Compressed() bool {
+	return c >= 0x10 && c < 0x80 && c&0x20 == 0x20
+}
+
+// charset returns character set of the text.
+// CodingDefault is returned for GSM 7bit, CodingLatin1 is returned
+// for SMSC default alphabet if it is not GSM, and CodingBinary is returned
+// for binary, compressed or unknown data.
+func (c DataCoding) charset(gsm bool) DataCoding {
+	switch {
+	case c == CodingDefault && !gsm:
+		return CodingLatin1
+	case c == CodingOctet, c == CodingPictogram, c == 0x0B, c == 0x0C, c == 0x0F:
+		return CodingBinary
+	case c < 0x10:
+		return c
+	case c.Compressed():
+		return CodingBinary
+	}
+	return c.alphabet()
+}
+
+// alphabet returns character set of GSM DCS.
+func (c DataCoding) alphabet() DataCoding {
+	switch {
+	case c < 0x80:
+		switch c & 0x0c {
+		case 0x00:
+			return CodingDefault
+		case 0x08:
+			return CodingUCS2
+		}
+	case c&0xe0 == 0xc0:
+		return CodingDefault
+	case c&0xf0 == 0xe0:
+		return CodingUCS2
+	case c&0xf4 == 0xf0:
+		return CodingDefault
+	}
+	return CodingBinary
+}
+
+// encoding returns encoding of the multibyte or national character set.
+// Extended Kanji is handled as EUC-JP that contains JIS X 0212.
+func (c DataCoding) encoding() encoding.Encoding {
+	switch c {
+	case CodingJIS:
+		return jisX0208{}
+	case CodingCyrillic:
+		return charmap.ISO8859_5
+	case CodingHebrew:
+		return charmap.ISO8859_8
+	case CodingISO2022JP:
+		return japanese.ISO2022JP
+	case CodingExtKanji:
+		return japanese.EUCJP
+	case CodingKSC5601:
+		return korean.EUCKR
+	}
+	return nil
+}
+
+// encodeText encodes the text with enc,
+// character that is not representable is replaced to '?',
+// or full width '？' if '?' is not representable, with ErrUnrepresentable.
+func encodeText(enc encoding.Encoding, text string) (_ []byte, e error) {
+	q := '?'
+	if _, e := enc.NewEncoder().String("?"); e != nil {
+		q = '？'
+	}
+	r := []rune(text)
+	for i, c := range r {
+		if _, ee := enc.NewEncoder().String(string(c)); ee != nil {
+			r[i] = q
+			e = ErrUnrepresentable
+		}
+	}
+	b, _ := enc.NewEncoder().String(string(r))
+	return []byte(b), e
+}
+
+// jisX0208 is JIS X 0208 that has two octets of row and cell for each character
+// without escape sequence. It is converted from/to EUC-JP.
+type jisX0208 struct{}
+
+func (jisX0208) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: jisDecoder{}}
+}
+
+func (jisX0208) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: jisEncoder{}}
+}
+
+type jisDecoder struct{ transform.NopResetter }
+
+func (jisDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, e error) {
+	for nSrc < len(src) {
+		if nSrc+1 == len(src) {
+			if atEOF {
+				return nDst, nSrc, errors.New("odd length of JIS X 0208 data")
+			}
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		b1, b2 := src[nSrc], src[nSrc+1]
+		if b1 < 0x21 || b1 > 0x7e || b2 < 0x21 || b2 > 0x7e {
+			return nDst, nSrc, errors.New("invalid JIS X 0208 data")
+		}
+		s, e := japanese.EUCJP.NewDecoder().Bytes([]byte{b1 | 0x80, b2 | 0x80})
+		if e != nil {
+			return nDst, nSrc, e
+		}
+		if len(dst)-nDst < len(s) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], s)
+		nSrc += 2
+	}
+	return
+}
+
+type jisEncoder struct{ transform.NopResetter }
+
+func (jisEncoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, e error) {
+	for nSrc < len(src) {
+		if !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, n := utf8.DecodeRune(src[nSrc:])
+		b, e := japanese.EUCJP.NewEncoder().String(string(r))
+		if e != nil || len(b) != 2 || b[0] < 0xa1 || b[1] < 0xa1 {
+			return nDst, nSrc, fmt.Errorf("%w: %q", ErrUnrepresentable, r)
+		}
+		if len(dst)-nDst < 2 {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		dst[nDst], dst[nDst+1] = b[0]&0x7f, b[1]&0x7f
+		nDst += 2
+		nSrc += n
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package smpp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestDataCodingGroups(t *testing.T) {
+	tests := []struct {
+		dc         DataCoding
+		charset    DataCoding
+		class      int // -1 for no class
+		compressed bool
+	}{
+		{0x00, CodingDefault, -1, false},
+		{0x01, CodingIA5, -1, false},
+		{0x02, CodingBinary, -1, false},
+		{0x03, CodingLatin1, -1, false},
+		{0x04, CodingBinary, -1, false},
+		{0x05, CodingJIS, -1, false},
+		{0x08, CodingUCS2, -1, false},
+		{0x09, CodingBinary, -1, false},
+		{0x0E, CodingKSC5601, -1, false},
+		{0x0F, CodingBinary, -1, false},
+		{0x10, CodingDefault, 0, false}, // general, class
+		{0x11, CodingDefault, 1, false},
+		{0x16, CodingBinary, 2, false},
+		{0x1B, CodingUCS2, 3, false},
+		{0x20, CodingBinary, -1, true}, // general, compressed
+		{0x38, CodingBinary, 0, true},
+		{0x48, CodingUCS2, -1, false}, // automatic deletion
+		{0x52, CodingDefault, 2, false},
+		{0xC0, CodingDefault, -1, false}, // message waiting, discard
+		{0xD8, CodingDefault, -1, false}, // message waiting, store
+		{0xE8, CodingUCS2, -1, false},    // message waiting, store UCS2
+		{0xF0, CodingDefault, 0, false},  // data coding/message class
+		{0xF3, CodingDefault, 3, false},
+		{0xF5, CodingBinary, 1, false},
+	}
+	for _, tt := range tests {
+		if c := tt.dc.charset(true); c != tt.charset {
+			t.Errorf("%s: charset %s, want %s", tt.dc, c, tt.charset)
+		}
+		m, ok := tt.dc.Class()
+		if !ok && tt.class != -1 || ok && int(m) != tt.class {
+			t.Errorf("%s: class %d %v, want %d", tt.dc, m, ok, tt.class)
+		}
+		if tt.dc.Compressed() != tt.compressed {
+			t.Errorf("%s: compressed %v", tt.dc, tt.dc.Compressed())
+		}
+	}
+	if c := CodingDefault.charset(false); c != CodingLatin1 {
+		t.Errorf("default is %s if it is not GSM", c)
+	}
+}
+
+func TestDataCodingJSON(t *testing.T) {
+	for dc, want := range map[DataCoding]string{
+		CodingDefault: `"default"`, CodingUCS2: `"ucs2"`, CodingKSC5601: `"ksc5601"`,
+		0x0B: `11`, 0xF1: `241`} {
+		b, e := json.Marshal(dc)
+		if e != nil || string(b) != want {
+			t.Errorf("%s is marshaled as %s, want %s", dc, b, want)
+		}
+		var c DataCoding
+		if e = json.Unmarshal(b, &c); e != nil || c != dc {
+			t.Errorf("%s: unmarshaled %s %v", b, c, e)
+		}
+	}
+	for s, dc := range map[string]DataCoding{`"ucs2"`: CodingUCS2, `"jis"`: CodingJIS, `"ksc5601"`: CodingKSC5601} {
+		var c DataCoding
+		if e := json.Unmarshal([]byte(s), &c); e != nil || c != dc {
+			t.Errorf("%s: unmarshaled %s %v", s, c, e)
+		}
+	}
+	var c DataCoding
+	if e := json.Unmarshal([]byte(`"unknown"`), &c); e == nil {
+		t.Error("unknown name is accepted")
+	}
+}
+
+func TestJISX0208(t *testing.T) {
+	tests := []struct {
+		text string
+		ud   string
+		err  error
+	}{
+		{"漢字", "34413b7a", nil},
+		{"あ、Ａ", "242221222341", nil},
+		{"a漢", "2129" + "3441", ErrUnrepresentable}, // 'a' is replaced to full width '？'
+	}
+	for _, tt := range tests {
+		u := UserData{Text: tt.text}
+		b, e := u.marshal(CodingJIS, true)
+		if hex.EncodeToString(b) != tt.ud || e != tt.err {
+			t.Errorf("%q: encoded %x %v, want %s", tt.text, b, e, tt.ud)
+		}
+	}
+	var u UserData
+	u.unmarshal([]byte{0x34, 0x41, 0x3b, 0x7a}, CodingJIS, false, true)
+	if u.Text != "漢字" {
+		t.Errorf("decoded %q", u.Text)
+	}
+	u.unmarshal([]byte{0xb4, 0xc1}, CodingJIS, false, true)
+	if u.Text != "b4c1" {
+		t.Errorf("invalid data is decoded as %q", u.Text)
+	}
+}
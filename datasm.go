@@ -19,7 +19,7 @@ type DataSM struct {
 	EsmClass esmClass                `json:"esm_class"`
 
 	RegisteredDelivery registeredDelivery `json:"registered_delivery"`
-	DataCoding         byte               `json:"data_coding"`
+	DataCoding         DataCoding         `json:"data_coding"`
 
 	Param OptionalParameters `json:"options,omitempty"`
 }
@@ -50,7 +50,7 @@ func (d *DataSM) Marshal(v byte) []byte {
 	writeAddr(d.DstTON, d.DstNPI, d.DstAddr, w)
 	d.EsmClass.writeTo(w)
 	d.RegisteredDelivery.writeTo(w)
-	w.WriteByte(d.DataCoding)
+	d.DataCoding.writeTo(w)
 	if v >= 0x34 {
 		d.Param.writeTo(w)
 	}
@@ -64,7 +64,7 @@ func (d *DataSM) Unmarshal(data []byte) (e error) {
 	} else if d.DstTON, d.DstNPI, d.DstAddr, e = readAddr(buf); e != nil {
 	} else if e = d.EsmClass.readFrom(buf); e != nil {
 	} else if e = d.RegisteredDelivery.readFrom(buf); e != nil {
-	} else if d.DataCoding, e = buf.ReadByte(); e != nil {
+	} else if e = d.DataCoding.readFrom(buf); e != nil {
 	} else {
 		d.Param = OptionalParameters{}
 		e = d.Param.readFrom(buf)
@@ -9,7 +9,7 @@ import (
 	"github.com/fkgi/smpp"
 )
 
-func HandleHTTP(w http.ResponseWriter, r *http.Request, req smpp.PDU, b *smpp.Bind) {
+func HandleHTTP(w http.ResponseWriter, r *http.Request, req smpp.PDU, s smpp.Sender) {
 	if r.Method != http.MethodPost {
 		w.Header().Add("Allow", "POST")
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -29,8 +29,12 @@ func HandleHTTP(w http.ResponseWriter, r *http.Request, req smpp.PDU, b *smpp.Bi
 		return
 	}
 
-	stat, res, e := b.Send(req)
-	if e != nil {
+	stat, res, e := s.SendContext(r.Context(), req)
+	if errors.Is(e, smpp.ErrNoBind) {
+		httpErr("no available SMPP bind", e.Error(),
+			http.StatusServiceUnavailable, w)
+		return
+	} else if e != nil {
 		httpErr("failed to send SMPP request", e.Error(),
 			http.StatusInternalServerError, w)
 		return
@@ -49,6 +53,34 @@ func HandleHTTP(w http.ResponseWriter, r *http.Request, req smpp.PDU, b *smpp.Bi
 		jsondata, e = json.Marshal(&SubmitSM_resp{
 			Status:        stat,
 			SubmitSM_resp: *res})
+	case *smpp.QuerySM_resp:
+		jsondata, e = json.Marshal(&QuerySM_resp{
+			Status:       stat,
+			QuerySM_resp: *res})
+	case *smpp.ReplaceSM_resp:
+		jsondata, e = json.Marshal(&ReplaceSM_resp{
+			Status:         stat,
+			ReplaceSM_resp: *res})
+	case *smpp.CancelSM_resp:
+		jsondata, e = json.Marshal(&CancelSM_resp{
+			Status:        stat,
+			CancelSM_resp: *res})
+	case *smpp.SubmitMultiSM_resp:
+		jsondata, e = json.Marshal(&SubmitMultiSM_resp{
+			Status:             stat,
+			SubmitMultiSM_resp: *res})
+	case *smpp.BroadcastSM_resp:
+		jsondata, e = json.Marshal(&BroadcastSM_resp{
+			Status:           stat,
+			BroadcastSM_resp: *res})
+	case *smpp.QueryBroadcastSM_resp:
+		jsondata, e = json.Marshal(&QueryBroadcastSM_resp{
+			Status:                stat,
+			QueryBroadcastSM_resp: *res})
+	case *smpp.CancelBroadcastSM_resp:
+		jsondata, e = json.Marshal(&CancelBroadcastSM_resp{
+			Status:                 stat,
+			CancelBroadcastSM_resp: *res})
 	default:
 		switch res.CommandID() {
 		case smpp.GenericNack:
@@ -30,17 +30,30 @@ func HandleSMPP(info smpp.BindInfo, req smpp.PDU) (smpp.StatusCode, smpp.PDU) {
 	case *smpp.DeliverSM:
 		path = "/smppmsg/v1/deliver"
 		res = &DeliverSM_resp{}
-		if info.BindType == smpp.TxBind {
-			smppErr("deliver_sm is notaccepted in Tx BIND", nil)
-			return smpp.StatInvCmdID, smpp.MakePDUof(smpp.GenericNack)
-		}
 	case *smpp.SubmitSM:
 		path = "/smppmsg/v1/submit"
 		res = &SubmitSM_resp{}
-		if info.BindType == smpp.RxBind {
-			smppErr("submit_sm is notaccepted in Rx BIND", nil)
-			return smpp.StatInvCmdID, smpp.MakePDUof(smpp.GenericNack)
-		}
+	case *smpp.SubmitMultiSM:
+		path = "/smppmsg/v1/submit_multi"
+		res = &SubmitMultiSM_resp{}
+	case *smpp.QuerySM:
+		path = "/smppmsg/v1/query"
+		res = &QuerySM_resp{}
+	case *smpp.ReplaceSM:
+		path = "/smppmsg/v1/replace"
+		res = &ReplaceSM_resp{}
+	case *smpp.CancelSM:
+		path = "/smppmsg/v1/cancel"
+		res = &CancelSM_resp{}
+	case *smpp.BroadcastSM:
+		path = "/smppmsg/v1/broadcast"
+		res = &BroadcastSM_resp{}
+	case *smpp.QueryBroadcastSM:
+		path = "/smppmsg/v1/query_broadcast"
+		res = &QueryBroadcastSM_resp{}
+	case *smpp.CancelBroadcastSM:
+		path = "/smppmsg/v1/cancel_broadcast"
+		res = &CancelBroadcastSM_resp{}
 	default:
 		smppErr("unknown SMPP request", nil)
 		return smpp.StatInvCmdID, smpp.MakePDUof(smpp.GenericNack)
@@ -80,6 +93,15 @@ func HandleSMPP(info smpp.BindInfo, req smpp.PDU) (smpp.StatusCode, smpp.PDU) {
 	return res.status(), res.unwrap()
 }
 
+// HandleSMPPAsync sends HTTP request in other goroutine and responds with the result.
+func HandleSMPPAsync(info smpp.BindInfo, req smpp.PDU, w *smpp.Responder) {
+	go func() {
+		if e := w.Respond(HandleSMPP(info, req)); e != nil {
+			smppErr("failed to send SMPP response", e)
+		}
+	}()
+}
+
 func smppErr(s string, e error) {
 	if NotifyHandlerError != nil {
 		if e != nil {
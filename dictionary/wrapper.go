@@ -31,6 +31,62 @@ type SubmitSM_resp struct {
 func (r *SubmitSM_resp) status() smpp.StatusCode { return r.Status }
 func (r *SubmitSM_resp) unwrap() smpp.PDU        { return &r.SubmitSM_resp }
 
+type QuerySM_resp struct {
+	Status smpp.StatusCode `json:"command_status"`
+	smpp.QuerySM_resp
+}
+
+func (r *QuerySM_resp) status() smpp.StatusCode { return r.Status }
+func (r *QuerySM_resp) unwrap() smpp.PDU        { return &r.QuerySM_resp }
+
+type ReplaceSM_resp struct {
+	Status smpp.StatusCode `json:"command_status"`
+	smpp.ReplaceSM_resp
+}
+
+func (r *ReplaceSM_resp) status() smpp.StatusCode { return r.Status }
+func (r *ReplaceSM_resp) unwrap() smpp.PDU        { return &r.ReplaceSM_resp }
+
+type CancelSM_resp struct {
+	Status smpp.StatusCode `json:"command_status"`
+	smpp.CancelSM_resp
+}
+
+func (r *CancelSM_resp) status() smpp.StatusCode { return r.Status }
+func (r *CancelSM_resp) unwrap() smpp.PDU        { return &r.CancelSM_resp }
+
+type SubmitMultiSM_resp struct {
+	Status smpp.StatusCode `json:"command_status"`
+	smpp.SubmitMultiSM_resp
+}
+
+func (r *SubmitMultiSM_resp) status() smpp.StatusCode { return r.Status }
+func (r *SubmitMultiSM_resp) unwrap() smpp.PDU        { return &r.SubmitMultiSM_resp }
+
+type BroadcastSM_resp struct {
+	Status smpp.StatusCode `json:"command_status"`
+	smpp.BroadcastSM_resp
+}
+
+func (r *BroadcastSM_resp) status() smpp.StatusCode { return r.Status }
+func (r *BroadcastSM_resp) unwrap() smpp.PDU        { return &r.BroadcastSM_resp }
+
+type QueryBroadcastSM_resp struct {
+	Status smpp.StatusCode `json:"command_status"`
+	smpp.QueryBroadcastSM_resp
+}
+
+func (r *QueryBroadcastSM_resp) status() smpp.StatusCode { return r.Status }
+func (r *QueryBroadcastSM_resp) unwrap() smpp.PDU        { return &r.QueryBroadcastSM_resp }
+
+type CancelBroadcastSM_resp struct {
+	Status smpp.StatusCode `json:"command_status"`
+	smpp.CancelBroadcastSM_resp
+}
+
+func (r *CancelBroadcastSM_resp) status() smpp.StatusCode { return r.Status }
+func (r *CancelBroadcastSM_resp) unwrap() smpp.PDU        { return &r.CancelBroadcastSM_resp }
+
 type GenericNack struct {
 	Status smpp.StatusCode `json:"command_status"`
 }
@@ -0,0 +1,46 @@
+package smpp
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrClosed             = errors.New("closed bind")
+	ErrTimeout            = errors.New("request timeout")
+	ErrWindowFull         = errors.New("window is full")
+	ErrInvalidHeader      = errors.New("invalid header")
+	ErrInvalidSequence    = errors.New("invalid sequence")
+	ErrUnexpectedRequest  = errors.New("unexpected request")
+	ErrUnexpectedResponse = errors.New("unexpected response")
+	ErrInvalidBindType    = errors.New("invalid bind type")
+	ErrIncorrectBindState = errors.New("incorrect bind status")
+	ErrUnsupported        = errors.New("unsupported request for interface version")
+	ErrMissingParam       = errors.New("expected optional parameter missing")
+	ErrNoBind             = errors.New("no active bind")
+	ErrServerClosed       = errors.New("server closed")
+	ErrServerStarted      = errors.New("server is already serving")
+	ErrNoDialer           = errors.New("no dialer")
+	ErrClientStarted      = errors.New("client is already started")
+	ErrResponded          = errors.New("request is already responded")
+	ErrUnrepresentable    = errors.New("character is not representable in the data_coding")
+	ErrMessageTooLong     = errors.New("short_message is too long for the interface version")
+	ErrInvalidUDH         = errors.New("invalid user data header")
+)
+
+// StatusError is error status of the command.
+// errors.Is matches StatusError that has same Status,
+// Command is also compared if it is not 0 in the target.
+type StatusError struct {
+	Command CommandID
+	Status  StatusCode
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Command, e.Status)
+}
+
+func (e StatusError) Is(target error) bool {
+	t, ok := target.(StatusError)
+	return ok && t.Status == e.Status && (t.Command == 0 || t.Command == e.Command)
+}
@@ -1,3 +1,5 @@
 module github.com/fkgi/smpp
 
 go 1.23
+
+require golang.org/x/text v0.28.0
@@ -0,0 +1,151 @@
+package smpp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestGSM7Pack(t *testing.T) {
+	tests := []struct {
+		text string
+		fill int
+		ud   string
+	}{
+		{"hellohello", 0, "e8329bfd4697d9ec37"},
+		{"1234567", 0, "31d98c56b3dd1a"}, // CR is added to 7 spare bits
+		{"12345678", 0, "31d98c56b3dd70"},
+		{"A", 1, "82"},
+		{"AB", 6, "405008"},
+	}
+	for _, tt := range tests {
+		septets, _ := gsm7Encode(tt.text, 0, 0)
+		ud := gsm7Pack(septets, tt.fill)
+		if hex.EncodeToString(ud) != tt.ud {
+			t.Errorf("%q fill %d: packed %x, want %s", tt.text, tt.fill, ud, tt.ud)
+		}
+		if s := gsm7Decode(gsm7Unpack(ud, tt.fill), 0, 0); s != tt.text {
+			t.Errorf("%q fill %d: unpacked %q", tt.text, tt.fill, s)
+		}
+	}
+}
+
+func TestGSM7RoundTrip(t *testing.T) {
+	text := strings.Repeat("@£$¥èé{}[]€", 3)
+	for fill := 0; fill < 7; fill++ {
+		for n := 1; n <= len([]rune(text)); n++ {
+			s := string([]rune(text)[:n])
+			septets, _ := gsm7Encode(s, 0, 0)
+			ud := gsm7Pack(septets, fill)
+			if got := gsm7Unpack(ud, fill); !bytes.Equal(got, septets) {
+				t.Fatalf("fill %d %q: %x, want %x", fill, s, got, septets)
+			}
+		}
+	}
+}
+
+func TestUserDataGSM7(t *testing.T) {
+	tests := []UserData{
+		{Text: "hello"},
+		{Text: "1234567"},
+		{Text: "€[]", UDH: []UserDataHdr{{Key: 0x00, Val: OctetData{1, 2, 1}}}},
+		{Text: "ğış", UDH: []UserDataHdr{{Key: 0x24, Val: OctetData{1}}}},
+		{Text: "ğış", UDH: []UserDataHdr{{Key: 0x25, Val: OctetData{1}}}},
+	}
+	for _, u := range tests {
+		b, e := u.marshal(CodingDefault, true)
+		if e != nil {
+			t.Fatal(e)
+		}
+		var v UserData
+		v.unmarshal(b, CodingDefault, len(u.UDH) != 0, true)
+		if v.Text != u.Text {
+			t.Errorf("%q: decoded %q", u.Text, v.Text)
+		}
+	}
+}
+
+func TestSelectShift(t *testing.T) {
+	tests := []struct {
+		text         string
+		lock, single byte
+		err          bool
+	}{
+		{"hello", 0, 0, false},
+		{"€{}", 0, 0, false},
+		{"Ğ", 1, 0, false},
+		{"ğışğışğışğış", 1, 0, false},
+		{"Ñí", 0, 2, false},
+		{"ÃÕãõêô", 3, 0, false},
+		{"नमस्ते", 6, 0, false},
+		{"नमस्ते १२", 6, 6, false},
+		{"வணக்கம்", 11, 0, false},
+		{"السلام", 13, 0, false},
+		{"😀", 0, 0, true},
+	}
+	for _, tt := range tests {
+		lock, single, e := selectShift(tt.text)
+		if (e != nil) != tt.err {
+			t.Errorf("%q: %v", tt.text, e)
+			continue
+		}
+		if lock != tt.lock || single != tt.single {
+			t.Errorf("%q: tables %d/%d, want %d/%d", tt.text, lock, single, tt.lock, tt.single)
+		}
+		if e == nil {
+			septets, _ := gsm7Encode(tt.text, lock, single)
+			if s := gsm7Decode(septets, lock, single); s != tt.text {
+				t.Errorf("%q: decoded %q", tt.text, s)
+			}
+		}
+	}
+}
+
+func TestIndianSingle(t *testing.T) {
+	for lang := byte(4); lang <= 13; lang++ {
+		s := singleTable(lang)
+		if s == singleTable(0) {
+			t.Fatalf("table %d is not registered", lang)
+		}
+		for r, c := range map[rune]byte{
+			'@': 0x00, '\f': 0x0A, '*': 0x0B, '¡': 0x13, '{': 0x28,
+			'\\': 0x2F, '|': 0x40, 'A': 0x41, 'Z': 0x5A, '€': 0x65} {
+			if s.singleEnc[r] != c || s.singleDec[c] != r {
+				t.Errorf("table %d: %q is 0x%02x", lang, r, s.singleEnc[r])
+			}
+		}
+	}
+	for _, tt := range []struct {
+		lang byte
+		r    rune
+		c    byte
+	}{
+		{4, '০', 0x1C}, {4, '৺', 0x36},
+		{6, '।', 0x19}, {6, '९', 0x25}, {6, '\u0951', 0x26}, {6, 'ॱ', 0x3A},
+		{12, '౦', 0x1C}, {13, '؀', 0x19}, {13, '۰', 0x1C}, {13, '۔', 0x3F},
+	} {
+		s := singleTable(tt.lang)
+		if s.singleEnc[tt.r] != tt.c || s.singleDec[tt.c] != tt.r {
+			t.Errorf("table %d: %q is 0x%02x", tt.lang, tt.r, s.singleEnc[tt.r])
+		}
+	}
+	if _, ok := singleTable(12).singleEnc['।']; ok {
+		t.Error("danda is in Telugu table")
+	}
+}
+
+func TestIndianLocking(t *testing.T) {
+	for lang := byte(4); lang <= 13; lang++ {
+		l := lockingTable(lang)
+		if l == lockingTable(0) {
+			t.Fatalf("table %d is not registered", lang)
+		}
+		if l.lockDec[0x1b] != 0x1b || l.lockEnc['a'] != 0x61 || l.lockEnc[' '] != 0x20 || l.lockEnc['\r'] != 0x0d {
+			t.Errorf("table %d is broken", lang)
+		}
+	}
+	if e := RegisterShiftTable(14, "abc", nil); e == nil {
+		t.Error("short locking table is registered")
+	}
+}
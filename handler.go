@@ -2,6 +2,7 @@ package smpp
 
 import (
 	"bufio"
+	"context"
 	"time"
 )
 
@@ -17,101 +18,155 @@ type message struct {
 
 func (b *Bind) serve(buf *bufio.ReadWriter) error {
 	b.eventQ = make(chan message, 1024)
-	b.reqStack = make(map[uint32]chan message)
-
-	enquireT := time.AfterFunc(KeepAlive, func() {
-		msg := message{
-			id:       EnquireLink,
-			seq:      b.nextSequence(),
-			callback: make(chan message)}
-		b.eventQ <- msg
-		wt := time.AfterFunc(Expire, func() {
-			b.eventQ <- message{
-				id:   internalFailure,
-				stat: 0xFFFFFFFF,
-				seq:  msg.seq}
-		})
-
-		msg = <-msg.callback
-		wt.Stop()
-		if msg.stat != 0x00000000 {
+	b.done = make(chan struct{})
+	b.pmu.Lock()
+	b.pending = make(map[Request]struct{})
+	b.closing = false
+	b.drained = nil
+	b.pmu.Unlock()
+	if b.conf.InboundRate > 0 {
+		b.rxLimit = &RateLimit{Rate: b.conf.InboundRate, Burst: b.conf.InboundBurst}
+	} else {
+		b.rxLimit = nil
+	}
+	if b.conf.OutboundRate > 0 || b.conf.AdaptiveRate {
+		b.txLimit = &RateLimit{Rate: b.conf.OutboundRate, Burst: b.conf.OutboundBurst}
+	} else {
+		b.txLimit = nil
+	}
+	if b.conf.Window > 0 {
+		b.window = make(chan struct{}, b.conf.Window)
+	} else {
+		b.window = nil
+	}
+
+	b.startWorkers()
+
+	enquireT := time.AfterFunc(b.conf.KeepAlive, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), b.conf.Expire)
+		defer cancel()
+		if msg := <-b.call(ctx, EnquireLink, nil); msg.stat != 0x00000000 {
 			b.Close()
 		}
 	})
 
 	// worker for event
 	go func() {
+		reqStack := make(map[uint32]chan message)
 		for {
 			msg := <-b.eventQ
 			var e error
 
 			if msg.callback != nil {
 				// Tx event
-				if msg.id < 0x80000000 {
+				if msg.id.IsRequest() && msg.callback != dummyCallback {
 					// Tx req
-					b.reqStack[msg.seq] = msg.callback
-					e = writePDU(buf, msg)
+					reqStack[msg.seq] = msg.callback
+					e = b.writePDU(buf, msg)
 				} else {
-					// Tx ans
-					e = writePDU(buf, msg)
+					// Tx ans or alert
+					e = b.writePDU(buf, msg)
 				}
 			} else {
 				// Rx event
 				if msg.id == closeConnection {
 					break
 				} else if msg.id == EnquireLink {
-					e = writePDU(buf, message{
+					e = b.writePDU(buf, message{
 						id:  EnquireLinkResp,
 						seq: msg.seq})
 				} else if msg.id == Unbind {
-					writePDU(buf, message{
+					b.writePDU(buf, message{
 						id:  UnbindResp,
 						seq: msg.seq})
 					b.con.Close()
 				} else if msg.id.IsRequest() {
 					// Rx other req
-					e = writePDU(buf, message{
+					e = b.writePDU(buf, message{
 						id:   GenericNack,
 						stat: StatInvCmdID,
 						seq:  msg.seq})
-				} else if callback, ok := b.reqStack[msg.seq]; ok {
+				} else if callback, ok := reqStack[msg.seq]; ok {
 					// Handle Rx ans
-					delete(b.reqStack, msg.seq)
+					delete(reqStack, msg.seq)
 					callback <- msg
 				}
 			}
 
 			if e == nil {
-				enquireT.Reset(KeepAlive)
+				enquireT.Reset(b.conf.KeepAlive)
 			} else {
 				b.con.Close()
 			}
 		}
-		b.reqStack = nil
+		close(b.done)
 	}()
 
+	// fields of the bind are published to other goroutines by active
+	b.active.Store(true)
+
 	// worker for Rx data from socket
-	for msg, e := readPDU(buf); e == nil; msg, e = readPDU(buf) {
+	for msg, e := b.readPDU(buf); e == nil; msg, e = b.readPDU(buf) {
+		if msg.id.IsRequest() && !b.permits(Rx, msg.id) {
+			if msg.id != AlertNotification {
+				b.reject(msg, StatInvBndSts)
+			}
+			continue
+		}
+
 		switch msg.id {
-		// case QuerySm:
-		case SubmitSm, DeliverSm, DataSm:
+		case QuerySm, SubmitSm, DeliverSm, ReplaceSm, CancelSm, SubmitMulti, DataSm:
+			b.dispatch(msg)
+		case BroadcastSm, QueryBroadcastSm, CancelBroadcastSm:
+			if b.ver < 0x50 {
+				b.eventQ <- msg
+			} else {
+				b.dispatch(msg)
+			}
+		case AlertNotification:
+			// no response, dropped if the queue is full
 			msg.bind = b
-			sharedQ <- msg
-		// case ReplaceSm:
-		// case CancelSm:
-		// case Outbind:
-		// case SubmitMulti:
+			b.enqueue(msg)
 		default:
 			b.eventQ <- msg
 		}
 	}
 
+	b.active.Store(false)
 	enquireT.Stop()
+	close(b.rxQ)
 	b.con.Close()
 	b.eventQ <- message{id: closeConnection}
-	if UnboundNotify != nil {
-		UnboundNotify(b.BindInfo, b.con.RemoteAddr())
+	if b.conf.UnboundNotify != nil {
+		b.conf.UnboundNotify(b.BindInfo, b.con.RemoteAddr())
 	}
 
 	return nil
 }
+
+// dispatch queues Rx request to the workers of the bind,
+// or rejects it if the bind is shutting down, throttled or the queue is full.
+func (b *Bind) dispatch(msg message) {
+	if !b.rxLimit.Allow() || !b.conf.SystemThrottle.Allow(b.PeerID) {
+		b.reject(msg, StatThrottled)
+		return
+	}
+	req := Request{Direction: Rx, CommandID: msg.id, Sequence: msg.seq}
+	if !b.track(req) {
+		b.reject(msg, StatInvBndSts)
+		return
+	}
+	msg.bind = b
+	if !b.enqueue(msg) {
+		b.untrack(req)
+		b.reject(msg, StatMsgQFul)
+	}
+}
+
+func (b *Bind) reject(msg message, stat StatusCode) {
+	b.eventQ <- message{
+		id:       msg.id | GenericNack,
+		stat:     stat,
+		seq:      msg.seq,
+		callback: dummyCallback}
+}
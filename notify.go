@@ -4,6 +4,7 @@ import "net"
 
 var BoundNotify func(BindInfo, net.Addr) = nil
 var UnboundNotify func(BindInfo, net.Addr) = nil
+var AlertNotify func(BindInfo, *AlertNotificationPDU) = nil
 var TraceMessage func(Direction, CommandID, StatusCode, uint32, []byte, error) = nil
 
 type Direction bool
@@ -6,7 +6,6 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 
@@ -28,8 +27,10 @@ func MakePDUof(c CommandID) PDU {
 		return &bindReq{cmd: c}
 	case BindReceiverResp, BindTransmitterResp, BindTransceiverResp:
 		return &bindRes{cmd: c}
-	// case QuerySm:
-	// case QuerySmResp:
+	case QuerySm:
+		return &QuerySM{}
+	case QuerySmResp:
+		return &QuerySM_resp{}
 	case SubmitSm:
 		return &SubmitSM{}
 	case SubmitSmResp:
@@ -42,31 +43,51 @@ func MakePDUof(c CommandID) PDU {
 		return &unbindReq{}
 	case UnbindResp:
 		return &unbindRes{}
-	//case ReplaceSm:
-	//case ReplaceSmResp:
-	//case CancelSm:
-	//case CancelSmResp:
-	//case Outbind:
+	case ReplaceSm:
+		return &ReplaceSM{}
+	case ReplaceSmResp:
+		return &ReplaceSM_resp{}
+	case CancelSm:
+		return &CancelSM{}
+	case CancelSmResp:
+		return &CancelSM_resp{}
+	case Outbind:
+		return &outbindReq{}
 	case EnquireLink:
 		return &enquireReq{}
 	case EnquireLinkResp:
 		return &enquireRes{}
-	// case SubmitMulti:
-	// case SubmitMultiResp:
-	// case AlertNotification:
+	case SubmitMulti:
+		return &SubmitMultiSM{}
+	case SubmitMultiResp:
+		return &SubmitMultiSM_resp{}
+	case AlertNotification:
+		return &AlertNotificationPDU{}
 	case DataSm:
 		return &DataSM{}
 	case DataSmResp:
 		return &DataSM_resp{}
+	case BroadcastSm:
+		return &BroadcastSM{}
+	case BroadcastSmResp:
+		return &BroadcastSM_resp{}
+	case QueryBroadcastSm:
+		return &QueryBroadcastSM{}
+	case QueryBroadcastSmResp:
+		return &QueryBroadcastSM_resp{}
+	case CancelBroadcastSm:
+		return &CancelBroadcastSM{}
+	case CancelBroadcastSmResp:
+		return &CancelBroadcastSM_resp{}
 	}
 	return nil
 }
 
-func readPDU(r *bufio.ReadWriter) (msg message, e error) {
+func (b *Bind) readPDU(r *bufio.ReadWriter) (msg message, e error) {
 	var l uint32
 	if e = binary.Read(r, binary.BigEndian, &l); e != nil {
 	} else if l < 16 {
-		e = errors.New("invalid header")
+		e = ErrInvalidHeader
 	} else if e = binary.Read(r, binary.BigEndian, &(msg.id)); e != nil {
 	} else if e = binary.Read(r, binary.BigEndian, &(msg.stat)); e != nil {
 	} else if e = binary.Read(r, binary.BigEndian, &(msg.seq)); e != nil {
@@ -75,13 +96,13 @@ func readPDU(r *bufio.ReadWriter) (msg message, e error) {
 		_, e = io.ReadFull(r, msg.body)
 	}
 
-	if TraceMessage != nil {
-		TraceMessage(Rx, msg.id, msg.stat, msg.seq, msg.body, e)
+	if b.conf.TraceMessage != nil {
+		b.conf.TraceMessage(Rx, msg.id, msg.stat, msg.seq, msg.body, e)
 	}
 	return
 }
 
-func writePDU(w *bufio.ReadWriter, msg message) (e error) {
+func (b *Bind) writePDU(w *bufio.ReadWriter, msg message) (e error) {
 	if msg.body == nil {
 		msg.body = []byte{}
 	}
@@ -95,12 +116,32 @@ func writePDU(w *bufio.ReadWriter, msg message) (e error) {
 		e = w.Flush()
 	}
 
-	if TraceMessage != nil {
-		TraceMessage(Tx, msg.id, msg.stat, msg.seq, msg.body, e)
+	if b.conf.TraceMessage != nil {
+		b.conf.TraceMessage(Tx, msg.id, msg.stat, msg.seq, msg.body, e)
 	}
 	return
 }
 
+// codedPDU is PDU that has text of the default alphabet.
+type codedPDU interface {
+	marshalText(v byte, gsm bool) ([]byte, error)
+	unmarshalText(data []byte, gsm bool) error
+}
+
+func (b *Bind) marshal(p PDU) ([]byte, error) {
+	if c, ok := p.(codedPDU); ok {
+		return c.marshalText(b.ver, *b.conf.DefaultAlphabetIsGSM)
+	}
+	return p.Marshal(b.ver), nil
+}
+
+func (b *Bind) unmarshal(p PDU, data []byte) error {
+	if c, ok := p.(codedPDU); ok {
+		return c.unmarshalText(data, *b.conf.DefaultAlphabetIsGSM)
+	}
+	return p.Unmarshal(data)
+}
+
 func readCString(buf *bytes.Buffer) (string, error) {
 	b, e := buf.ReadBytes(0x00)
 	if e != nil {
@@ -0,0 +1,207 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Sender is interface to send request PDU, it is implemented by Bind, Client and Pool.
+type Sender interface {
+	SendContext(context.Context, PDU) (StatusCode, PDU, error)
+	IsActive() bool
+	InFlight() int
+}
+
+type poolStrategy byte
+
+const (
+	RoundRobin poolStrategy = iota
+	LeastInFlight
+	Weighted
+)
+
+func (s poolStrategy) String() string {
+	switch s {
+	case RoundRobin:
+		return "round-robin"
+	case LeastInFlight:
+		return "least-in-flight"
+	case Weighted:
+		return "weighted"
+	}
+	return "unknown"
+}
+
+// Pool sends request PDU by one of the member binds.
+type Pool struct {
+	Strategy poolStrategy
+	// Penalty is duration to skip the member that answered StatThrottled or timed out.
+	// Default is 5 seconds.
+	Penalty time.Duration
+
+	mu      sync.Mutex
+	members []*poolMember
+	next    int
+}
+
+type poolMember struct {
+	s       Sender
+	weight  int
+	current int
+	until   time.Time
+}
+
+// Add adds member to the pool. weight is used by Weighted strategy.
+func (p *Pool) Add(s Sender, weight int) {
+	if weight < 1 {
+		weight = 1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.members = append(p.members, &poolMember{s: s, weight: weight})
+}
+
+func (p *Pool) Remove(s Sender) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, m := range p.members {
+		if m.s == s {
+			p.members = append(p.members[:i], p.members[i+1:]...)
+			return
+		}
+	}
+}
+
+// Members returns senders in the pool.
+func (p *Pool) Members() []Sender {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := make([]Sender, len(p.members))
+	for i, m := range p.members {
+		r[i] = m.s
+	}
+	return r
+}
+
+func (p *Pool) IsActive() bool {
+	for _, s := range p.Members() {
+		if s.IsActive() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Pool) InFlight() (n int) {
+	for _, s := range p.Members() {
+		n += s.InFlight()
+	}
+	return
+}
+
+func (p *Pool) Send(r PDU) (StatusCode, PDU, error) {
+	return p.SendContext(context.Background(), r)
+}
+
+// SendContext sends request PDU by selected member.
+// Member whose bind type does not permit the request is skipped.
+// The request is sent again by other member if the bind is closed
+// or is not able to send it.
+func (p *Pool) SendContext(ctx context.Context, r PDU) (StatusCode, PDU, error) {
+	tried := map[*poolMember]bool{}
+	for {
+		m := p.selectMember(tried, r.CommandID())
+		if m == nil {
+			return StatSysErr, nil, ErrNoBind
+		}
+		tried[m] = true
+
+		stat, res, e := m.s.SendContext(ctx, r)
+		switch {
+		case errors.Is(e, ErrClosed), errors.Is(e, ErrNoBind),
+			errors.Is(e, ErrIncorrectBindState), errors.Is(e, ErrInvalidBindType):
+			if ctx.Err() == nil {
+				continue
+			}
+		case errors.Is(e, ErrTimeout), stat == StatThrottled:
+			p.penalize(m)
+		}
+		return stat, res, e
+	}
+}
+
+func (p *Pool) penalize(m *poolMember) {
+	d := p.Penalty
+	if d == 0 {
+		d = time.Second * 5
+	}
+	p.mu.Lock()
+	m.until = time.Now().Add(d)
+	p.mu.Unlock()
+}
+
+// selectMember returns active member that is not in tried and permits id.
+// Penalized member is selected only if no other member is available.
+func (p *Pool) selectMember(tried map[*poolMember]bool, id CommandID) *poolMember {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	healthy := []*poolMember{}
+	penalized := []*poolMember{}
+	for _, m := range p.members {
+		if tried[m] || !m.s.IsActive() || !permitsTx(m.s, id) {
+		} else if now.Before(m.until) {
+			penalized = append(penalized, m)
+		} else {
+			healthy = append(healthy, m)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = penalized
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	switch p.Strategy {
+	case LeastInFlight:
+		r := healthy[0]
+		n := r.s.InFlight()
+		for _, m := range healthy[1:] {
+			if i := m.s.InFlight(); i < n {
+				r, n = m, i
+			}
+		}
+		return r
+	case Weighted:
+		var r *poolMember
+		total := 0
+		for _, m := range healthy {
+			m.current += m.weight
+			total += m.weight
+			if r == nil || m.current > r.current {
+				r = m
+			}
+		}
+		r.current -= total
+		return r
+	}
+	p.next++
+	return healthy[p.next%len(healthy)]
+}
+
+// permitsTx returns false if bind type of s does not permit to send id.
+func permitsTx(s Sender, id CommandID) bool {
+	switch s := s.(type) {
+	case *Bind:
+		return s.permits(Tx, id)
+	case *Client:
+		if b := s.Bind(); b != nil {
+			return b.permits(Tx, id)
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package smpp
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type testSender struct {
+	active   bool
+	inflight int
+	stat     StatusCode
+	err      error
+	n        int
+}
+
+func (s *testSender) SendContext(context.Context, PDU) (StatusCode, PDU, error) {
+	s.n++
+	return s.stat, nil, s.err
+}
+func (s *testSender) IsActive() bool { return s.active }
+func (s *testSender) InFlight() int  { return s.inflight }
+
+func TestPoolStrategy(t *testing.T) {
+	tests := []struct {
+		strategy poolStrategy
+		weight   [3]int
+		inflight [3]int
+		n        [3]int
+	}{
+		{RoundRobin, [3]int{1, 1, 1}, [3]int{}, [3]int{4, 4, 4}},
+		{Weighted, [3]int{3, 2, 1}, [3]int{}, [3]int{6, 4, 2}},
+		{LeastInFlight, [3]int{1, 1, 1}, [3]int{2, 0, 1}, [3]int{0, 12, 0}},
+	}
+	for _, tt := range tests {
+		p := &Pool{Strategy: tt.strategy}
+		s := [3]*testSender{}
+		for i := range s {
+			s[i] = &testSender{active: true, inflight: tt.inflight[i]}
+			p.Add(s[i], tt.weight[i])
+		}
+		for i := 0; i < 12; i++ {
+			if _, _, e := p.Send(&SubmitSM{}); e != nil {
+				t.Fatal(e)
+			}
+		}
+		for i := range s {
+			if s[i].n != tt.n[i] {
+				t.Errorf("%s: member %d sent %d, want %d", tt.strategy, i, s[i].n, tt.n[i])
+			}
+		}
+	}
+}
+
+func TestPoolPenalty(t *testing.T) {
+	a, b := &testSender{active: true}, &testSender{active: true}
+	p := &Pool{}
+	p.Add(a, 1)
+	p.Add(b, 1)
+
+	a.stat = StatThrottled
+	for i := 0; i < 4; i++ {
+		p.Send(&SubmitSM{})
+	}
+	// a is skipped after StatThrottled
+	if a.n != 1 || b.n != 3 {
+		t.Fatal(a.n, b.n)
+	}
+	// penalized member is used if no other member is available
+	b.active = false
+	if s, _, _ := p.Send(&SubmitSM{}); s != StatThrottled || a.n != 2 {
+		t.Fatal(s, a.n)
+	}
+
+	c := &testSender{active: true, err: ErrTimeout}
+	p = &Pool{}
+	p.Add(c, 1)
+	p.Add(&testSender{active: true}, 1)
+	for i := 0; i < 4; i++ {
+		p.Send(&SubmitSM{})
+	}
+	if c.n != 1 {
+		t.Fatal(c.n)
+	}
+}
+
+func TestPoolRetry(t *testing.T) {
+	for _, fail := range []error{ErrClosed, ErrNoBind, ErrIncorrectBindState} {
+		a, b := &testSender{active: true, err: fail}, &testSender{active: true}
+		p := &Pool{}
+		p.Add(a, 1)
+		p.Add(b, 1)
+		for i := 0; i < 4; i++ {
+			if _, _, e := p.Send(&SubmitSM{}); e != nil {
+				t.Fatal(e)
+			}
+		}
+		if b.n != 4 {
+			t.Errorf("%v: sent %d by other member", fail, b.n)
+		}
+		b.err = fail
+		if _, _, e := p.Send(&SubmitSM{}); e != ErrNoBind {
+			t.Errorf("%v: %v if all members fail", fail, e)
+		}
+	}
+	if _, _, e := (&Pool{}).Send(&SubmitSM{}); e != ErrNoBind {
+		t.Fatal(e)
+	}
+}
+
+func TestPoolBinds(t *testing.T) {
+	p := &Pool{}
+	for _, bt := range []bindtype{TxBind, RxBind, TRxBind} {
+		smsc := &Bind{Config: testConfig(okHandler)}
+		esme := &Bind{BindInfo: BindInfo{BindType: bt}, Config: testConfig(okHandler)}
+		pipe(t, smsc, esme)
+		p.Add(smsc, 1)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s, _, e := p.Send(&DeliverSM{}); e != nil || s != StatOK {
+				t.Error(s, e)
+			}
+		}()
+	}
+	wg.Wait()
+	// no member permits submit_sm from SMSC
+	if _, _, e := p.Send(&SubmitSM{}); e != ErrNoBind {
+		t.Fatal(e)
+	}
+}
@@ -0,0 +1,166 @@
+package smpp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fkgi/teldata"
+)
+
+type messageState byte
+
+const (
+	StateScheduled     messageState = 0x00
+	StateEnroute       messageState = 0x01
+	StateDelivered     messageState = 0x02
+	StateExpired       messageState = 0x03
+	StateDeleted       messageState = 0x04
+	StateUndeliverable messageState = 0x05
+	StateAccepted      messageState = 0x06
+	StateUnknown       messageState = 0x07
+	StateRejected      messageState = 0x08
+	StateSkipped       messageState = 0x09
+)
+
+func (s messageState) String() string {
+	switch s {
+	case StateScheduled:
+		return "SCHEDULED"
+	case StateEnroute:
+		return "ENROUTE"
+	case StateDelivered:
+		return "DELIVERED"
+	case StateExpired:
+		return "EXPIRED"
+	case StateDeleted:
+		return "DELETED"
+	case StateUndeliverable:
+		return "UNDELIVERABLE"
+	case StateAccepted:
+		return "ACCEPTED"
+	case StateUnknown:
+		return "UNKNOWN"
+	case StateRejected:
+		return "REJECTED"
+	case StateSkipped:
+		return "SKIPPED"
+	}
+	return "reserved"
+}
+
+func (s messageState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *messageState) UnmarshalJSON(b []byte) (e error) {
+	str := ""
+	if e = json.Unmarshal(b, &str); e != nil {
+		return
+	}
+	switch str {
+	case "SCHEDULED":
+		*s = StateScheduled
+	case "ENROUTE":
+		*s = StateEnroute
+	case "DELIVERED":
+		*s = StateDelivered
+	case "EXPIRED":
+		*s = StateExpired
+	case "DELETED":
+		*s = StateDeleted
+	case "UNDELIVERABLE":
+		*s = StateUndeliverable
+	case "ACCEPTED":
+		*s = StateAccepted
+	case "UNKNOWN":
+		*s = StateUnknown
+	case "REJECTED":
+		*s = StateRejected
+	case "SKIPPED":
+		*s = StateSkipped
+	default:
+		e = errors.New("invalid Message State: " + str)
+	}
+	return
+}
+
+type QuerySM struct {
+	MessageID string                  `json:"id"`
+	SrcTON    teldata.NatureOfAddress `json:"src_ton,omitempty"`
+	SrcNPI    teldata.NumberingPlan   `json:"src_npi,omitempty"`
+	SrcAddr   string                  `json:"src_addr,omitempty"`
+}
+
+func (d *QuerySM) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "message_id     :", d.MessageID)
+	fmt.Fprintln(buf, Indent, "source_addr_ton:", d.SrcTON)
+	fmt.Fprintln(buf, Indent, "source_addr_npi:", d.SrcNPI)
+	fmt.Fprint(buf, Indent, " source_addr    : ", d.SrcAddr)
+	return buf.String()
+}
+
+func (*QuerySM) CommandID() CommandID { return QuerySm }
+
+func (d *QuerySM) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.MessageID), w)
+	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
+	return w.Bytes()
+}
+
+func (d *QuerySM) Unmarshal(data []byte) (e error) {
+	buf := bytes.NewBuffer(data)
+	if d.MessageID, e = readCString(buf); e == nil {
+		d.SrcTON, d.SrcNPI, d.SrcAddr, e = readAddr(buf)
+	}
+	return
+}
+
+type QuerySM_resp struct {
+	MessageID string       `json:"id"`
+	FinalDate string       `json:"final_date,omitempty"`
+	State     messageState `json:"message_state"`
+	ErrorCode byte         `json:"error_code"`
+}
+
+func (d *QuerySM_resp) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "message_id   :", d.MessageID)
+	fmt.Fprintln(buf, Indent, "final_date   :", d.FinalDate)
+	fmt.Fprintln(buf, Indent, "message_state:", d.State)
+	fmt.Fprint(buf, Indent, " error_code   : ", d.ErrorCode)
+	return buf.String()
+}
+
+func (*QuerySM_resp) CommandID() CommandID { return QuerySmResp }
+
+func (d *QuerySM_resp) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.MessageID), w)
+	writeCString([]byte(d.FinalDate), w)
+	w.WriteByte(byte(d.State))
+	w.WriteByte(d.ErrorCode)
+	return w.Bytes()
+}
+
+func (d *QuerySM_resp) Unmarshal(data []byte) (e error) {
+	if len(data) == 0 {
+		return
+	}
+	buf := bytes.NewBuffer(data)
+	var s byte
+	if d.MessageID, e = readCString(buf); e != nil {
+	} else if d.FinalDate, e = readCString(buf); e != nil {
+	} else if s, e = buf.ReadByte(); e != nil {
+	} else {
+		d.State = messageState(s)
+		d.ErrorCode, e = buf.ReadByte()
+	}
+	return
+}
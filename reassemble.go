@@ -0,0 +1,237 @@
+package smpp
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fkgi/teldata"
+)
+
+// Reassembler buffers segments of concatenated SubmitSM and DeliverSM,
+// and calls Handler with the complete message.
+// HandleRequest is used as RequestHandler of the bind,
+// so it is not called if AsyncHandler of the bind is set.
+// Text of each segment is decoded separately, then a UCS2 surrogate pair
+// that is split into two segments is not restored.
+type Reassembler struct {
+	Handler func(BindInfo, PDU) (StatusCode, PDU)
+	// PartHandler answers each segment that does not complete the message.
+	// Default answers StatOK with generated message_id.
+	PartHandler func(BindInfo, PDU) (StatusCode, PDU)
+	// Timeout is max time to wait the all segments, default is 1 minute.
+	Timeout time.Duration
+	// ExpireNotify is called with received segments when Timeout is expired
+	// or the message is dropped by MaxSets.
+	ExpireNotify func(BindInfo, []PDU)
+	// MaxSets is max number of the messages that wait segments, default is 1000.
+	// The oldest message is dropped when segment of a new message is received over MaxSets.
+	MaxSets int
+
+	mu     sync.Mutex
+	sets   map[concatKey]*concatSet
+	serial uint64
+}
+
+type concatKey struct {
+	peer  string
+	cmd   CommandID
+	src   string
+	dst   string
+	ref   uint16
+	total byte
+}
+
+type concatSet struct {
+	info   BindInfo
+	parts  []PDU
+	count  int
+	serial uint64
+	timer  *time.Timer
+}
+
+// HandleRequest is used as RequestHandler.
+func (r *Reassembler) HandleRequest(info BindInfo, p PDU) (StatusCode, PDU) {
+	var sm *smPDU
+	var res PDU
+	switch v := p.(type) {
+	case *SubmitSM:
+		sm, res = &v.smPDU, &SubmitSM_resp{}
+	case *DeliverSM:
+		sm, res = &v.smPDU, &DeliverSM_resp{}
+	}
+	if sm == nil {
+		return r.handle(info, p)
+	}
+	ref, total, seq, ok := sm.concatInfo()
+	if !ok || total < 2 {
+		return r.handle(info, p)
+	}
+	if seq == 0 || seq > total {
+		return StatInvOptParamVal, res
+	}
+
+	key := concatKey{
+		peer:  info.PeerID,
+		cmd:   p.CommandID(),
+		src:   addrKey(sm.SrcTON, sm.SrcNPI, sm.SrcAddr),
+		dst:   addrKey(sm.DstTON, sm.DstNPI, sm.DstAddr),
+		ref:   ref,
+		total: total}
+
+	r.mu.Lock()
+	if r.sets == nil {
+		r.sets = make(map[concatKey]*concatSet)
+	}
+	var dropped *concatSet
+	set, ok := r.sets[key]
+	if !ok {
+		dropped = r.dropOldest()
+		timeout := r.Timeout
+		if timeout == 0 {
+			timeout = time.Minute
+		}
+		r.serial++
+		set = &concatSet{info: info, parts: make([]PDU, total), serial: r.serial}
+		set.timer = time.AfterFunc(timeout, func() { r.expire(key, set) })
+		r.sets[key] = set
+	}
+	if set.parts[seq-1] == nil {
+		set.count++
+	}
+	set.parts[seq-1] = p
+	if set.count != int(total) {
+		r.mu.Unlock()
+		r.notify(dropped)
+		if r.PartHandler != nil {
+			return r.PartHandler(info, p)
+		}
+		if _, ok := res.(*SubmitSM_resp); ok {
+			res = &SubmitSM_resp{MessageID: fmt.Sprintf("%016x", rand.Uint64())}
+		}
+		return StatOK, res
+	}
+	delete(r.sets, key)
+	set.timer.Stop()
+	r.mu.Unlock()
+	r.notify(dropped)
+
+	return r.handle(info, merge(set.parts))
+}
+
+func (r *Reassembler) handle(info BindInfo, p PDU) (StatusCode, PDU) {
+	if r.Handler == nil {
+		return StatSysErr, nil
+	}
+	return r.Handler(info, p)
+}
+
+func (r *Reassembler) expire(key concatKey, set *concatSet) {
+	r.mu.Lock()
+	if r.sets[key] != set {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.sets, key)
+	r.mu.Unlock()
+	r.notify(set)
+}
+
+// dropOldest removes the oldest set if number of the sets reaches MaxSets.
+// r.mu must be locked.
+func (r *Reassembler) dropOldest() *concatSet {
+	max := r.MaxSets
+	if max <= 0 {
+		max = 1000
+	}
+	if len(r.sets) < max {
+		return nil
+	}
+	var key concatKey
+	var set *concatSet
+	for k, s := range r.sets {
+		if set == nil || s.serial < set.serial {
+			key, set = k, s
+		}
+	}
+	delete(r.sets, key)
+	set.timer.Stop()
+	return set
+}
+
+func (r *Reassembler) notify(set *concatSet) {
+	if set == nil || r.ExpireNotify == nil {
+		return
+	}
+	parts := []PDU{}
+	for _, p := range set.parts {
+		if p != nil {
+			parts = append(parts, p)
+		}
+	}
+	r.ExpireNotify(set.info, parts)
+}
+
+func addrKey(ton teldata.NatureOfAddress, npi teldata.NumberingPlan, addr string) string {
+	return string([]byte{byte(ton), byte(npi)}) + addr
+}
+
+// concatInfo returns concatenation information in UDH or SAR parameters.
+func (d *smPDU) concatInfo() (ref uint16, total, seq byte, ok bool) {
+	for _, h := range d.ShortMessage.UDH {
+		if h.Key == 0x00 && len(h.Val) == 3 {
+			return uint16(h.Val[0]), h.Val[1], h.Val[2], true
+		}
+		if h.Key == 0x08 && len(h.Val) == 4 {
+			return uint16(h.Val[0])<<8 | uint16(h.Val[1]), h.Val[2], h.Val[3], true
+		}
+	}
+	r, ok1 := d.Param[sarMsgRefNum]
+	t, ok2 := d.Param[sarTotalSegments]
+	s, ok3 := d.Param[sarSegmentSeqnum]
+	if ok1 && ok2 && ok3 && len(r) == 2 && len(t) == 1 && len(s) == 1 {
+		return uint16(r[0])<<8 | uint16(r[1]), t[0], s[0], true
+	}
+	return
+}
+
+// merge returns PDU that has text of all parts,
+// other fields are copied from the first part.
+func merge(parts []PDU) PDU {
+	var sm smPDU
+	text := new(strings.Builder)
+	for i, p := range parts {
+		var d *smPDU
+		switch v := p.(type) {
+		case *SubmitSM:
+			d = &v.smPDU
+		case *DeliverSM:
+			d = &v.smPDU
+		}
+		if i == 0 {
+			sm = *d
+		}
+		text.WriteString(d.ShortMessage.Text)
+	}
+
+	udh := sm.ShortMessage.UDH
+	sm.ShortMessage = UserData{Text: text.String()}
+	for _, h := range udh {
+		if h.Key != 0x00 && h.Key != 0x08 {
+			sm.ShortMessage.UDH = append(sm.ShortMessage.UDH, h)
+		}
+	}
+	sm.EsmClass.UDHI = len(sm.ShortMessage.UDH) != 0
+	sm.Param = maps.Clone(sm.Param)
+	delete(sm.Param, sarMsgRefNum)
+	delete(sm.Param, sarTotalSegments)
+	delete(sm.Param, sarSegmentSeqnum)
+
+	if _, ok := parts[0].(*SubmitSM); ok {
+		return &SubmitSM{smPDU: sm}
+	}
+	return &DeliverSM{smPDU: sm}
+}
@@ -0,0 +1,120 @@
+package smpp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReassembler(t *testing.T) {
+	seg := func(seq byte, text string) *SubmitSM {
+		p := &SubmitSM{}
+		p.ShortMessage = UserData{Text: text, UDH: []UserDataHdr{{Key: 0x00, Val: OctetData{1, 3, seq}}}}
+		return p
+	}
+	tests := []struct {
+		name  string
+		parts []*SubmitSM
+		text  string
+	}{
+		{"in order", []*SubmitSM{seg(1, "ab"), seg(2, "cd"), seg(3, "ef")}, "abcdef"},
+		{"out of order", []*SubmitSM{seg(3, "ef"), seg(1, "ab"), seg(2, "cd")}, "abcdef"},
+		{"duplicate", []*SubmitSM{seg(1, "ab"), seg(1, "ab"), seg(2, "cd"), seg(3, "ef")}, "abcdef"},
+	}
+	for _, tt := range tests {
+		var got []string
+		r := &Reassembler{Handler: func(_ BindInfo, p PDU) (StatusCode, PDU) {
+			got = append(got, p.(*SubmitSM).ShortMessage.Text)
+			return StatOK, &SubmitSM_resp{MessageID: "final"}
+		}}
+		ids := map[string]bool{}
+		for i, p := range tt.parts {
+			s, res := r.HandleRequest(BindInfo{}, p)
+			if s != StatOK {
+				t.Fatalf("%s: status %s", tt.name, s)
+			}
+			id := res.(*SubmitSM_resp).MessageID
+			if i != len(tt.parts)-1 && (id == "" || ids[id]) {
+				t.Errorf("%s: message_id %q of part %d", tt.name, id, i)
+			}
+			ids[id] = true
+		}
+		if len(got) != 1 || got[0] != tt.text {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.text)
+		}
+	}
+}
+
+func TestReassemblerExpire(t *testing.T) {
+	expired := make(chan []PDU, 1)
+	r := &Reassembler{
+		Timeout: 10 * time.Millisecond,
+		Handler: func(BindInfo, PDU) (StatusCode, PDU) {
+			t.Error("incomplete message is handled")
+			return StatOK, nil
+		},
+		PartHandler: func(BindInfo, PDU) (StatusCode, PDU) {
+			return StatOK, &SubmitSM_resp{MessageID: "part"}
+		},
+		ExpireNotify: func(_ BindInfo, p []PDU) { expired <- p }}
+	p := &SubmitSM{}
+	p.Param = OptionalParameters{
+		sarMsgRefNum:     {0, 1},
+		sarTotalSegments: {2},
+		sarSegmentSeqnum: {2}}
+	if _, res := r.HandleRequest(BindInfo{}, p); res.(*SubmitSM_resp).MessageID != "part" {
+		t.Fatal("PartHandler is not called")
+	}
+	select {
+	case parts := <-expired:
+		if len(parts) != 1 || parts[0] != p {
+			t.Fatal(parts)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not expired")
+	}
+	if len(r.sets) != 0 {
+		t.Fatal("expired set is not removed")
+	}
+}
+
+func TestReassemblerMaxSets(t *testing.T) {
+	dropped := []PDU{}
+	handled := 0
+	r := &Reassembler{
+		MaxSets: 2,
+		Handler: func(BindInfo, PDU) (StatusCode, PDU) {
+			handled++
+			return StatOK, &SubmitSM_resp{}
+		},
+		ExpireNotify: func(_ BindInfo, p []PDU) { dropped = append(dropped, p...) }}
+	defer func() {
+		for _, s := range r.sets {
+			s.timer.Stop()
+		}
+	}()
+	part := func(ref, seq byte) *SubmitSM {
+		p := &SubmitSM{}
+		p.Param = OptionalParameters{
+			sarMsgRefNum:     {0, ref},
+			sarTotalSegments: {2},
+			sarSegmentSeqnum: {seq}}
+		return p
+	}
+
+	first := part(1, 1)
+	r.HandleRequest(BindInfo{}, first)
+	r.HandleRequest(BindInfo{}, part(2, 1))
+	if len(dropped) != 0 {
+		t.Fatal("dropped under MaxSets")
+	}
+	r.HandleRequest(BindInfo{}, part(3, 1))
+	if len(dropped) != 1 || dropped[0] != first || len(r.sets) != 2 {
+		t.Fatal("oldest set is not dropped", dropped, len(r.sets))
+	}
+
+	// segment of existing message does not drop other message
+	r.HandleRequest(BindInfo{}, part(3, 2))
+	if handled != 1 || len(dropped) != 1 || len(r.sets) != 1 {
+		t.Fatal(handled, len(dropped), len(r.sets))
+	}
+}
@@ -0,0 +1,75 @@
+package smpp
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fkgi/teldata"
+)
+
+type ReplaceSM struct {
+	MessageID            string                  `json:"id"`
+	SrcTON               teldata.NatureOfAddress `json:"src_ton,omitempty"`
+	SrcNPI               teldata.NumberingPlan   `json:"src_npi,omitempty"`
+	SrcAddr              string                  `json:"src_addr,omitempty"`
+	ScheduleDeliveryTime string                  `json:"schedule_delivery_time,omitempty"`
+	ValidityPeriod       string                  `json:"validity_period,omitempty"`
+	RegisteredDelivery   registeredDelivery      `json:"registered_delivery"`
+	SmDefaultMsgId       byte                    `json:"sm_default_sm_id,omitempty"`
+	// SmLength            byte
+	ShortMessage OctetData `json:"short_message,omitempty"`
+}
+
+func (d *ReplaceSM) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "message_id            :", d.MessageID)
+	fmt.Fprintln(buf, Indent, "source_addr_ton       :", d.SrcTON)
+	fmt.Fprintln(buf, Indent, "source_addr_npi       :", d.SrcNPI)
+	fmt.Fprintln(buf, Indent, "source_addr           :", d.SrcAddr)
+	fmt.Fprintln(buf, Indent, "schedule_delivery_time:", d.ScheduleDeliveryTime)
+	fmt.Fprintln(buf, Indent, "validity_period       :", d.ValidityPeriod)
+	fmt.Fprintln(buf, Indent, "registered_delivery   :", d.RegisteredDelivery)
+	fmt.Fprintln(buf, Indent, "sm_default_msg_id     :", d.SmDefaultMsgId)
+	fmt.Fprintf(buf, "%s short_message         : % x", Indent, []byte(d.ShortMessage))
+	return buf.String()
+}
+
+func (*ReplaceSM) CommandID() CommandID { return ReplaceSm }
+
+func (d *ReplaceSM) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.MessageID), w)
+	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
+	writeCString([]byte(d.ScheduleDeliveryTime), w)
+	writeCString([]byte(d.ValidityPeriod), w)
+	d.RegisteredDelivery.writeTo(w)
+	w.WriteByte(d.SmDefaultMsgId)
+	w.WriteByte(byte(len(d.ShortMessage)))
+	w.Write(d.ShortMessage)
+	return w.Bytes()
+}
+
+func (d *ReplaceSM) Unmarshal(data []byte) (e error) {
+	buf := bytes.NewBuffer(data)
+	var l byte
+	if d.MessageID, e = readCString(buf); e != nil {
+	} else if d.SrcTON, d.SrcNPI, d.SrcAddr, e = readAddr(buf); e != nil {
+	} else if d.ScheduleDeliveryTime, e = readCString(buf); e != nil {
+	} else if d.ValidityPeriod, e = readCString(buf); e != nil {
+	} else if e = d.RegisteredDelivery.readFrom(buf); e != nil {
+	} else if d.SmDefaultMsgId, e = buf.ReadByte(); e != nil {
+	} else if l, e = buf.ReadByte(); e == nil {
+		d.ShortMessage = make([]byte, int(l))
+		_, e = buf.Read(d.ShortMessage)
+	}
+	return
+}
+
+type ReplaceSM_resp struct{}
+
+func (*ReplaceSM_resp) String() string         { return "" }
+func (*ReplaceSM_resp) CommandID() CommandID   { return ReplaceSmResp }
+func (*ReplaceSM_resp) Marshal(byte) []byte    { return []byte{} }
+func (*ReplaceSM_resp) Unmarshal([]byte) error { return nil }
@@ -0,0 +1,47 @@
+package smpp
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var AsyncHandler func(BindInfo, PDU, *Responder) = nil
+
+// Responder sends response of Rx request from AsyncHandler.
+// Only one response is sent for each request.
+// The request is counted in MaxQueued until it is responded.
+type Responder struct {
+	msg      message
+	deadline time.Time
+	timer    *time.Timer
+	done     atomic.Bool
+}
+
+func newResponder(msg message, timeout time.Duration) *Responder {
+	queued.Add(1)
+	r := &Responder{msg: msg, deadline: time.Now().Add(timeout)}
+	r.timer = time.AfterFunc(timeout, func() {
+		if r.done.CompareAndSwap(false, true) {
+			r.msg.bind.respond(r.msg, StatSysErr, nil)
+			queued.Add(-1)
+		}
+	})
+	return r
+}
+
+// Respond sends the response. Response without body is sent if res is nil.
+// ErrResponded is returned if the response is already sent or the deadline is exceeded.
+func (r *Responder) Respond(stat StatusCode, res PDU) error {
+	if !r.done.CompareAndSwap(false, true) {
+		return ErrResponded
+	}
+	r.timer.Stop()
+	r.msg.bind.respond(r.msg, stat, res)
+	queued.Add(-1)
+	return nil
+}
+
+// Deadline returns time that StatSysErr is answered automatically.
+func (r *Responder) Deadline() time.Time {
+	return r.deadline
+}
@@ -0,0 +1,56 @@
+package smpp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResponder(t *testing.T) {
+	responders := make(chan *Responder, 2)
+	conf := testConfig(nil)
+	conf.ResponseTimeout = 50 * time.Millisecond
+	conf.AsyncHandler = func(_ BindInfo, _ PDU, r *Responder) { responders <- r }
+	smsc := &Bind{Config: conf}
+	esme := &Bind{Config: testConfig(okHandler)}
+	pipe(t, smsc, esme)
+	base := queued.Load()
+
+	// responded by the handler
+	res := make(chan Response, 1)
+	go func() {
+		s, p, e := esme.Send(&SubmitSM{})
+		res <- Response{Status: s, PDU: p, Err: e}
+	}()
+	r := <-responders
+	if d := time.Until(r.Deadline()); d <= 0 || d > conf.ResponseTimeout {
+		t.Fatal(d)
+	}
+	if e := r.Respond(StatOK, &SubmitSM_resp{MessageID: "async"}); e != nil {
+		t.Fatal(e)
+	}
+	if a := <-res; a.Err != nil || a.Status != StatOK || a.PDU.(*SubmitSM_resp).MessageID != "async" {
+		t.Fatal(a)
+	}
+	if e := r.Respond(StatOK, nil); e != ErrResponded {
+		t.Fatal(e)
+	}
+
+	// answered by timeout
+	go func() {
+		s, p, e := esme.Send(&SubmitSM{})
+		res <- Response{Status: s, PDU: p, Err: e}
+	}()
+	r = <-responders
+	if a := <-res; a.Err != nil || a.Status != StatSysErr {
+		t.Fatal(a)
+	}
+	if e := r.Respond(StatOK, &SubmitSM_resp{}); e != ErrResponded {
+		t.Fatal(e)
+	}
+	for i := 0; queued.Load() != base; i++ {
+		if i == 100 {
+			t.Fatal(queued.Load()-base, "requests are left in queue")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -1,11 +1,10 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -13,6 +12,7 @@ import (
 	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -65,6 +65,9 @@ func main() {
 	smpp.DefaultAlphabetIsGSM = getEnumEnv("DEFAULT_ALPHABET", "ascii", "gsm7bit") == "gsm7bit"
 	smpp.Expire = getDurationEnv(os.Getenv("TIMEOUT"), smpp.Expire)
 	smpp.KeepAlive = getDurationEnv(os.Getenv("ENQUIRE_INTERVAL"), smpp.KeepAlive)
+	if getEnumEnv("INTERFACE_VERSION", "3.4", "5.0") == "5.0" {
+		smpp.InterfaceVersion = 0x50
+	}
 	if getEnumEnv("VERBOSE", "no", "yes") == "no" {
 		smpp.TraceMessage = nil
 	}
@@ -84,7 +87,7 @@ func main() {
 			log.Fatalln("[ERROR]", "invalid HTTP backend host:", e)
 		} else {
 			log.Println("[INFO]", "HTTP backend:", dictionary.Backend)
-			smpp.RequestHandler = dictionary.HandleSMPP
+			smpp.AsyncHandler = dictionary.HandleSMPPAsync
 		}
 	}
 
@@ -126,8 +129,13 @@ func main() {
 			log.Fatalln("[ERROR]", "invalid local address:", e)
 		}
 	}
+	useTLS := os.Getenv("TLS") == "yes"
 
-	dsts := []*net.TCPAddr{}
+	binds := []*smpp.Client{}
+	pool := &smpp.Pool{}
+	if getEnumEnv("BALANCING", "roundrobin", "least_inflight") == "least_inflight" {
+		pool.Strategy = smpp.LeastInFlight
+	}
 	for i := range 10 {
 		a := os.Getenv(fmt.Sprintf("PEER_ADDR%d", i))
 		if a == "" {
@@ -136,22 +144,69 @@ func main() {
 		if _, _, e := net.SplitHostPort(a); e != nil {
 			a = a + ":2775"
 		}
-		if a, e := net.ResolveTCPAddr("tcp", a); e != nil {
+		dst, e := net.ResolveTCPAddr("tcp", a)
+		if e != nil {
 			log.Fatalln("[ERROR]", "invalid destination address", e)
-		} else {
-			dsts = append(dsts, a)
 		}
+
+		n := len(binds)
+		binds = append(binds, &smpp.Client{
+			BindInfo: info,
+			Dialer: func(ctx context.Context) (net.Conn, error) {
+				d := net.Dialer{LocalAddr: localAddr}
+				c, e := d.DialContext(ctx, "tcp", dst.String())
+				if e == nil && useTLS {
+					c = tls.Client(c, &tls.Config{InsecureSkipVerify: true})
+				}
+				return c, e
+			},
+			MaxBackoff: time.Second * 30,
+			StateNotify: func(s smpp.ClientState, e error) {
+				if e != nil {
+					log.Println("[INFO]", "bind", n, ":", s, "from", dst, ", error=", e)
+				} else {
+					log.Println("[INFO]", "bind", n, ":", s, "to", dst, info.BindType)
+				}
+			}})
+		pool.Add(binds[n], 1)
 	}
-	binds := make([]*smpp.Bind, len(dsts))
 
 	if info.BindType != smpp.RxBind {
 		http.HandleFunc("POST /smppmsg/v1/data",
 			func(w http.ResponseWriter, r *http.Request) {
-				handleHTTP(w, r, &smpp.DataSM{}, binds)
+				dictionary.HandleHTTP(w, r, &smpp.DataSM{}, pool)
 			})
 		http.HandleFunc("POST /smppmsg/v1/submit",
 			func(w http.ResponseWriter, r *http.Request) {
-				handleHTTP(w, r, &smpp.SubmitSM{}, binds)
+				dictionary.HandleHTTP(w, r, &smpp.SubmitSM{}, pool)
+			})
+		http.HandleFunc("POST /smppmsg/v1/submit_multi",
+			func(w http.ResponseWriter, r *http.Request) {
+				dictionary.HandleHTTP(w, r, &smpp.SubmitMultiSM{}, pool)
+			})
+		http.HandleFunc("POST /smppmsg/v1/query",
+			func(w http.ResponseWriter, r *http.Request) {
+				dictionary.HandleHTTP(w, r, &smpp.QuerySM{}, pool)
+			})
+		http.HandleFunc("POST /smppmsg/v1/replace",
+			func(w http.ResponseWriter, r *http.Request) {
+				dictionary.HandleHTTP(w, r, &smpp.ReplaceSM{}, pool)
+			})
+		http.HandleFunc("POST /smppmsg/v1/cancel",
+			func(w http.ResponseWriter, r *http.Request) {
+				dictionary.HandleHTTP(w, r, &smpp.CancelSM{}, pool)
+			})
+		http.HandleFunc("POST /smppmsg/v1/broadcast",
+			func(w http.ResponseWriter, r *http.Request) {
+				dictionary.HandleHTTP(w, r, &smpp.BroadcastSM{}, pool)
+			})
+		http.HandleFunc("POST /smppmsg/v1/query_broadcast",
+			func(w http.ResponseWriter, r *http.Request) {
+				dictionary.HandleHTTP(w, r, &smpp.QueryBroadcastSM{}, pool)
+			})
+		http.HandleFunc("POST /smppmsg/v1/cancel_broadcast",
+			func(w http.ResponseWriter, r *http.Request) {
+				dictionary.HandleHTTP(w, r, &smpp.CancelBroadcastSM{}, pool)
 			})
 	}
 	if len(frontend) != 0 {
@@ -163,107 +218,24 @@ func main() {
 		}()
 	}
 
-	closer := make(chan map[*net.TCPAddr]func(), 1)
-	closer <- map[*net.TCPAddr]func(){}
-	for i := range dsts {
-		go func(i int) {
-			for {
-				if cl := <-closer; cl == nil {
-					closer <- cl
-					break
-				} else {
-					closer <- cl
-				}
-
-				binds[i] = &smpp.Bind{BindInfo: info}
-				log.Println("[INFO]", "bind", i, ": connecting SMPP", binds[i].BindType, "bind to", dsts[i])
-				var c net.Conn
-				var e error
-				if c, e = net.DialTCP("tcp", localAddr, dsts[i]); e != nil {
-					binds[i] = nil
-					log.Println("[ERROR]", "bind", i, ": closed, error=", e)
-					if o, ok := e.(*net.OpError); !ok || !o.Timeout() {
-						time.Sleep(time.Second * 30)
-					}
-					continue
-				}
-				if os.Getenv("TLS") == "yes" {
-					c = tls.Client(c, &tls.Config{InsecureSkipVerify: true})
-				}
-
-				if cl := <-closer; cl != nil {
-					cl[dsts[i]] = binds[i].Close
-					closer <- cl
-				} else {
-					closer <- cl
-					break
-				}
-
-				log.Println("[INFO]", "bind", i, ": closed, error=", binds[i].DialAndServe(c))
-				binds[i] = nil
-
-				if cl := <-closer; cl != nil {
-					delete(cl, dsts[i])
-					closer <- cl
-				} else {
-					closer <- cl
-					break
-				}
-				time.Sleep(time.Second * 30)
-			}
-		}(i)
+	for _, c := range binds {
+		c.Start()
 	}
 
 	sigc := make(chan os.Signal, 1)
 	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	if call := <-sigc; call != nil {
 		log.Println("[INFO]", "closing binds")
-		cl := <-closer
-		for _, v := range cl {
-			v()
-		}
-		closer <- nil
-
-		for w := true; w; {
-			w = false
-			for _, b := range binds {
-				if b != nil {
-					w = true
-					time.Sleep(time.Millisecond * 100)
-				}
-			}
+		wg := sync.WaitGroup{}
+		for _, c := range binds {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Close()
+			}()
 		}
+		wg.Wait()
 	}
 	dictionary.Client.CloseIdleConnections()
 	log.Println("[INFO]", "server stopped")
 }
-
-func handleHTTP(w http.ResponseWriter, r *http.Request, req smpp.PDU, b []*smpp.Bind) {
-	offset := rand.Intn(len(b))
-	if b[offset] != nil && b[offset].IsActive() {
-		dictionary.HandleHTTP(w, r, req, b[offset])
-		return
-	}
-
-	for i := offset + 1; i < len(b); i++ {
-		if b[i] != nil && b[i].IsActive() {
-			dictionary.HandleHTTP(w, r, req, b[i])
-			return
-		}
-	}
-	for i := 0; i < offset; i++ {
-		if b[i] != nil && b[i].IsActive() {
-			dictionary.HandleHTTP(w, r, req, b[i])
-			return
-		}
-	}
-
-	data, _ := json.Marshal(struct {
-		T string `json:"title"`
-		D string `json:"detail"`
-	}{T: "failed to send request", D: "no SMPP connection is available"})
-
-	w.Header().Add("Content-Type", "application/problem+json")
-	w.WriteHeader(http.StatusInternalServerError)
-	w.Write(data)
-}
@@ -42,6 +42,10 @@ func init() {
 		log.Println("[INFO]", buf)
 	}
 
+	smpp.AlertNotify = func(i smpp.BindInfo, a *smpp.AlertNotificationPDU) {
+		log.Println("[INFO]", "alert notification from", i.PeerID, a)
+	}
+
 	dictionary.NotifyHandlerError = func(proto, msg string) {
 		log.Println("[ERROR]", "error in", proto, "with reason", msg)
 	}
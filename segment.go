@@ -0,0 +1,350 @@
+package smpp
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"sync/atomic"
+)
+
+type concatMethod byte
+
+const (
+	ConcatUDH8 concatMethod = iota
+	ConcatUDH16
+	ConcatSAR
+	ConcatPayload
+)
+
+func (m concatMethod) String() string {
+	switch m {
+	case ConcatUDH8:
+		return "8bit_udh"
+	case ConcatUDH16:
+		return "16bit_udh"
+	case ConcatSAR:
+		return "sar"
+	case ConcatPayload:
+		return "message_payload"
+	}
+	return "unknown"
+}
+
+const (
+	sarMsgRefNum     uint16 = 0x020C
+	sarTotalSegments uint16 = 0x020E
+	sarSegmentSeqnum uint16 = 0x020F
+	messagePayload   uint16 = 0x0424
+)
+
+var concatRef atomic.Uint32
+
+// Splitter splits long text to segments of SubmitSM or DeliverSM.
+type Splitter struct {
+	Method concatMethod
+	// GSM is used for data_coding 0x00 instead of DefaultAlphabetIsGSM if it is not nil.
+	GSM *bool
+	// GSMCoding is data_coding of GSM 7bit that AutoCoding selects
+	// if data_coding 0x00 is not GSM, such as 0xF1 (GSM 7bit, class 1).
+	GSMCoding DataCoding
+}
+
+// SubmitSM returns SubmitSM PDUs that have the text.
+// Other fields of the PDUs are copied from base.
+func (s Splitter) SubmitSM(base *SubmitSM, text string) ([]*SubmitSM, error) {
+	p, e := s.split(&base.smPDU, text)
+	r := make([]*SubmitSM, len(p))
+	for i := range p {
+		r[i] = &SubmitSM{smPDU: p[i]}
+	}
+	return r, e
+}
+
+// DeliverSM returns DeliverSM PDUs that have the text.
+// Other fields of the PDUs are copied from base.
+func (s Splitter) DeliverSM(base *DeliverSM, text string) ([]*DeliverSM, error) {
+	p, e := s.split(&base.smPDU, text)
+	r := make([]*DeliverSM, len(p))
+	for i := range p {
+		r[i] = &DeliverSM{smPDU: p[i]}
+	}
+	return r, e
+}
+
+// gsm returns true if data_coding 0x00 is GSM 7bit.
+func (s Splitter) gsm() bool {
+	if s.GSM != nil {
+		return *s.GSM
+	}
+	return DefaultAlphabetIsGSM
+}
+
+func (s Splitter) split(base *smPDU, text string) ([]smPDU, error) {
+	gsm := s.gsm()
+	segment := func(ud UserData) smPDU {
+		p := *base
+		p.Param = maps.Clone(base.Param)
+		if p.Param == nil {
+			p.Param = OptionalParameters{}
+		}
+		p.ShortMessage = ud
+		return p
+	}
+
+	dc := base.DataCoding.charset(gsm)
+	var lock, single byte
+	if dc == CodingDefault {
+		var e error
+		if lock, single, e = selectShift(text); e != nil {
+			return nil, e
+		}
+	}
+	national := shiftHeader(lock, single)
+	units, total, e := splitUnits(text, dc, lock, single)
+	if e != nil {
+		return nil, e
+	}
+	if s.Method == ConcatPayload {
+		p := segment(UserData{})
+		p.Param[messagePayload], _ = UserData{Text: text, UDH: national}.marshal(base.DataCoding, gsm)
+		p.EsmClass.UDHI = len(national) != 0
+		return []smPDU{p}, nil
+	}
+	if total <= segmentSize(dc, udhSize(national, 0)) {
+		return []smPDU{segment(UserData{Text: text, UDH: national})}, nil
+	}
+
+	size := s.segmentSize(dc, national)
+	if size == 0 {
+		return nil, errors.New("invalid concatenation method")
+	}
+	texts := joinUnits(units, size)
+	if len(texts) > 255 {
+		return nil, errors.New("too many segments")
+	}
+
+	ref := concatRef.Add(1)
+	r := make([]smPDU, len(texts))
+	for i, t := range texts {
+		ud := UserData{Text: t}
+		n, seq := byte(len(texts)), byte(i+1)
+		switch s.Method {
+		case ConcatUDH8:
+			ud.UDH = []UserDataHdr{{Key: 0x00, Val: OctetData{byte(ref), n, seq}}}
+		case ConcatUDH16:
+			ud.UDH = []UserDataHdr{{Key: 0x08, Val: OctetData{byte(ref >> 8), byte(ref), n, seq}}}
+		}
+		ud.UDH = append(ud.UDH, national...)
+		r[i] = segment(ud)
+		if s.Method == ConcatSAR {
+			r[i].Param[sarMsgRefNum] = []byte{byte(ref >> 8), byte(ref)}
+			r[i].Param[sarTotalSegments] = []byte{n}
+			r[i].Param[sarSegmentSeqnum] = []byte{seq}
+		}
+	}
+	return r, nil
+}
+
+// TextInfo is size of the text encoded by DataCoding.
+// Length and PerSegment are number of septets for GSM 7bit, or octets for others.
+// LockingShift and SingleShift are national language identifier of the shift tables.
+type TextInfo struct {
+	DataCoding   DataCoding `json:"data_coding"`
+	Length       int        `json:"length"`
+	Segments     int        `json:"segments"`
+	PerSegment   int        `json:"per_segment"`
+	LockingShift byte       `json:"locking_shift,omitempty"`
+	SingleShift  byte       `json:"single_shift,omitempty"`
+}
+
+// Analyze returns size of the text encoded by dc.
+// ErrUnrepresentable is returned if dc can't represent the text.
+func (s Splitter) Analyze(text string, dc DataCoding) (TextInfo, error) {
+	i := TextInfo{DataCoding: dc}
+	c := dc.charset(s.gsm())
+	if c == CodingDefault {
+		var e error
+		if i.LockingShift, i.SingleShift, e = selectShift(text); e != nil {
+			return i, e
+		}
+	}
+	national := shiftHeader(i.LockingShift, i.SingleShift)
+	units, total, e := splitUnits(text, c, i.LockingShift, i.SingleShift)
+	if e != nil {
+		return i, e
+	}
+	i.Length = total
+	i.Segments = 1
+	i.PerSegment = segmentSize(c, udhSize(national, 0))
+	if s.Method == ConcatPayload {
+		i.PerSegment = total
+	} else if total > i.PerSegment {
+		i.PerSegment = s.segmentSize(c, national)
+		i.Segments = len(joinUnits(units, i.PerSegment))
+	}
+	return i, nil
+}
+
+// AutoCoding selects data_coding of the text from GSM 7bit default alphabet,
+// Latin-1 and UCS2 in this order, and returns size of the text.
+// GSM 7bit is selected as data_coding 0x00 if it is GSM, or as GSMCoding.
+// GSM 7bit is not selected if data_coding 0x00 is not GSM and GSMCoding is not GSM 7bit.
+func (s Splitter) AutoCoding(text string) TextInfo {
+	dc := CodingDefault
+	if !s.gsm() {
+		dc = s.GSMCoding
+	}
+	if dc.charset(s.gsm()) == CodingDefault {
+		if i, e := s.Analyze(text, dc); e == nil {
+			return i
+		}
+	}
+	if i, e := s.Analyze(text, CodingLatin1); e == nil {
+		return i
+	}
+	i, _ := s.Analyze(text, CodingUCS2)
+	return i
+}
+
+func (s Splitter) segmentSize(dc DataCoding, national []UserDataHdr) int {
+	switch s.Method {
+	case ConcatUDH8:
+		return segmentSize(dc, udhSize(national, 5))
+	case ConcatUDH16:
+		return segmentSize(dc, udhSize(national, 6))
+	case ConcatSAR:
+		return segmentSize(dc, udhSize(national, 0))
+	}
+	return 0
+}
+
+// udhSize returns octets of UDH that has h and concatenation IE of c octets.
+func udhSize(h []UserDataHdr, c int) int {
+	for _, e := range h {
+		c += len(e.Val) + 2
+	}
+	if c != 0 {
+		c++
+	}
+	return c
+}
+
+// shiftHeader returns UDH of national language shift tables.
+func shiftHeader(lock, single byte) (h []UserDataHdr) {
+	if lock != 0 {
+		h = append(h, UserDataHdr{Key: 0x25, Val: OctetData{lock}})
+	}
+	if single != 0 {
+		h = append(h, UserDataHdr{Key: 0x24, Val: OctetData{single}})
+	}
+	return
+}
+
+// selectShift selects national language shift tables
+// that represent the text with least septets.
+func selectShift(text string) (lock, single byte, e error) {
+	best := -1
+	try := func(l, s byte) {
+		n := len(shiftHeader(l, s)) * 4
+		for _, r := range text {
+			c := gsm7Septets(r, l, s)
+			if c == 0 {
+				return
+			}
+			n += c
+		}
+		if best < 0 || n < best {
+			best, lock, single = n, l, s
+		}
+	}
+	try(0, 0)
+	for _, l := range shiftLanguages() {
+		try(0, l)
+		if lockingTable(l) != lockingTable(0) {
+			try(l, 0)
+			try(l, l)
+		}
+	}
+	if best < 0 {
+		e = fmt.Errorf("%w: %q", ErrUnrepresentable, text)
+	}
+	return
+}
+
+// segmentSize returns size of the text in a segment with h octets UDH,
+// in septets for GSM 7bit or in octets for others.
+func segmentSize(dc DataCoding, h int) int {
+	if dc == CodingDefault {
+		return (140 - h) * 8 / 7
+	}
+	return 140 - h
+}
+
+type textUnit struct {
+	text string
+	size int
+}
+
+// joinUnits joins units to texts that are not longer than size.
+func joinUnits(units []textUnit, size int) []string {
+	texts := []string{}
+	l := 0
+	for _, u := range units {
+		if len(texts) == 0 || l+u.size > size {
+			texts = append(texts, "")
+			l = 0
+		}
+		texts[len(texts)-1] += u.text
+		l += u.size
+	}
+	return texts
+}
+
+// splitUnits splits the text to units that must not be separated.
+// Size of the unit for ISO-2022-JP contains escape sequences before and after it.
+func splitUnits(text string, dc DataCoding, lock, single byte) (units []textUnit, total int, e error) {
+	switch dc {
+	case CodingBinary:
+		if _, e = hex.DecodeString(text); e != nil {
+			e = fmt.Errorf("invalid binary data: %w", e)
+			return
+		}
+		for i := 0; i+1 < len(text); i += 2 {
+			units = append(units, textUnit{text: text[i : i+2], size: 1})
+		}
+	default:
+		for _, r := range text {
+			u := textUnit{text: string(r)}
+			switch dc {
+			case CodingDefault:
+				u.size = gsm7Septets(r, lock, single)
+			case CodingIA5:
+				if r <= 0x7f {
+					u.size = 1
+				}
+			case CodingLatin1:
+				if r <= 0xff {
+					u.size = 1
+				}
+			case CodingUCS2:
+				if u.size = 2; r > 0xffff {
+					u.size = 4
+				}
+			default:
+				if b, e := dc.encoding().NewEncoder().String(u.text); e == nil {
+					u.size = len(b)
+				}
+			}
+			if u.size == 0 {
+				e = fmt.Errorf("%w: %q", ErrUnrepresentable, r)
+				return
+			}
+			units = append(units, u)
+		}
+	}
+	for _, u := range units {
+		total += u.size
+	}
+	return
+}
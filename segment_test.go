@@ -0,0 +1,200 @@
+package smpp
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSplitterSegments(t *testing.T) {
+	gsm := true
+	tests := []struct {
+		text string
+		dc   DataCoding
+		n    [4]int // UDH8, UDH16, SAR, payload
+	}{
+		{strings.Repeat("a", 160), CodingDefault, [4]int{1, 1, 1, 1}},
+		{strings.Repeat("a", 161), CodingDefault, [4]int{2, 2, 2, 1}},
+		{strings.Repeat("a", 306), CodingDefault, [4]int{2, 3, 2, 1}},
+		{strings.Repeat("{", 153), CodingDefault, [4]int{3, 3, 2, 1}},
+		{strings.Repeat("あ", 70), CodingUCS2, [4]int{1, 1, 1, 1}},
+		{strings.Repeat("あ", 134), CodingUCS2, [4]int{2, 3, 2, 1}},
+		{strings.Repeat("é", 141), CodingLatin1, [4]int{2, 2, 2, 1}},
+		{strings.Repeat("ab", 140), CodingBinary, [4]int{1, 1, 1, 1}},
+		{strings.Repeat("ab", 141), CodingBinary, [4]int{2, 2, 2, 1}},
+	}
+	for _, tt := range tests {
+		for m, n := range tt.n {
+			s := Splitter{Method: concatMethod(m), GSM: &gsm}
+			base := &SubmitSM{}
+			base.DataCoding = tt.dc
+			p, e := s.SubmitSM(base, tt.text)
+			if e != nil {
+				t.Fatal(e)
+			}
+			if len(p) != n {
+				t.Errorf("%s %s(%d): %d segments, want %d", s.Method, tt.dc, len(tt.text), len(p), n)
+			}
+			if i, _ := s.Analyze(tt.text, tt.dc); i.Segments != n {
+				t.Errorf("%s %s(%d): analyzed %d segments, want %d", s.Method, tt.dc, len(tt.text), i.Segments, n)
+			}
+			for _, q := range p {
+				if ud, _ := q.ShortMessage.marshal(q.DataCoding, gsm); len(ud) > 140 {
+					t.Errorf("%s %s(%d): %d octets user data", s.Method, tt.dc, len(tt.text), len(ud))
+				}
+			}
+		}
+	}
+}
+
+func TestSplitterInvalidBinary(t *testing.T) {
+	for _, text := range []string{"012", "zz", "01g2"} {
+		base := &SubmitSM{}
+		base.DataCoding = CodingBinary
+		if _, e := (Splitter{}).SubmitSM(base, text); e == nil {
+			t.Errorf("%q is accepted", text)
+		}
+	}
+}
+
+func TestMessagePayload(t *testing.T) {
+	gsm := false
+	text := strings.Repeat("x", 300)
+	p := &SubmitSM{}
+	p.DataCoding = CodingLatin1
+	p.ShortMessage.Text = text
+
+	if _, e := p.marshalText(0x33, gsm); !errors.Is(e, ErrMessageTooLong) {
+		t.Fatal(e)
+	}
+	b, e := p.marshalText(0x34, gsm)
+	if e != nil {
+		t.Fatal(e)
+	}
+	q := &SubmitSM{}
+	if e = q.unmarshalText(b, gsm); e != nil {
+		t.Fatal(e)
+	}
+	if q.ShortMessage.Text != text {
+		t.Fatal("text is not moved from message_payload")
+	}
+	if len(q.Param[messagePayload]) != 300 {
+		t.Fatal("message_payload is removed")
+	}
+	// marshaled again without duplicated text
+	if b2, _ := q.marshalText(0x34, gsm); len(b2) != len(b) {
+		t.Fatal(len(b2), len(b))
+	}
+}
+
+func TestMessagePayloadMulti(t *testing.T) {
+	gsm := false
+	text := strings.Repeat("x", 300)
+	p := &SubmitMultiSM{DstAddrs: []DestAddress{{Flag: SMEAddress, DstAddr: "1"}}}
+	p.DataCoding = CodingLatin1
+	p.ShortMessage.Text = text
+
+	if _, e := p.marshalText(0x33, gsm); !errors.Is(e, ErrMessageTooLong) {
+		t.Fatal(e)
+	}
+	b, e := p.marshalText(0x34, gsm)
+	if e != nil {
+		t.Fatal(e)
+	}
+	q := &SubmitMultiSM{}
+	if e = q.unmarshalText(b, gsm); e != nil {
+		t.Fatal(e)
+	}
+	if q.ShortMessage.Text != text || len(q.DstAddrs) != 1 {
+		t.Fatal("text is not moved from message_payload")
+	}
+	if b2, _ := q.marshalText(0x34, gsm); len(b2) != len(b) {
+		t.Fatal(len(b2), len(b))
+	}
+
+	// short_message is shorter than sm_length
+	p.ShortMessage.Text = "abc"
+	b, _ = p.marshalText(0x34, gsm)
+	if e = q.unmarshalText(b[:len(b)-2], gsm); e == nil {
+		t.Fatal("truncated short_message is accepted")
+	}
+}
+
+func TestInvalidUDH(t *testing.T) {
+	for _, ud := range [][]byte{
+		{0x05, 0x00, 0x03, 0x01}, // UDHL is longer than user data
+		{0x03, 0x00, 0x03, 0x01}, // IE is longer than UDH
+		{0x01, 0x00}} {           // no IE length
+		u := UserData{}
+		if e := u.unmarshal(ud, CodingLatin1, true, false); e != ErrInvalidUDH {
+			t.Errorf("%x: %v", ud, e)
+		}
+	}
+
+	p := &SubmitSM{}
+	p.DataCoding = CodingLatin1
+	p.ShortMessage.UDH = []UserDataHdr{{Key: 0x00, Val: []byte{1, 2, 1}}}
+	p.ShortMessage.Text = "abc"
+	b, _ := p.marshalText(0x34, false)
+	// UDHL at the head of short_message
+	i := bytes.Index(b, []byte{0x05, 0x00, 0x03})
+	b[i] = 0x40
+	if e := (&SubmitSM{}).unmarshalText(b, false); e != ErrInvalidUDH {
+		t.Fatal(e)
+	}
+}
+
+func TestAutoCodingGSMCoding(t *testing.T) {
+	gsm := false
+	text := strings.Repeat("a", 160)
+	if i := (Splitter{GSM: &gsm}).AutoCoding(text); i.DataCoding != CodingLatin1 || i.Segments != 2 {
+		t.Fatal("GSM is selected without GSMCoding", i.DataCoding)
+	}
+	s := Splitter{GSM: &gsm, GSMCoding: 0xF1}
+	if i := s.AutoCoding(text); i.DataCoding != 0xF1 || i.Segments != 1 || i.Length != 160 {
+		t.Fatal(i.DataCoding, i.Segments, i.Length)
+	}
+	if i := s.AutoCoding("あ"); i.DataCoding != CodingUCS2 {
+		t.Fatal(i.DataCoding)
+	}
+	// GSMCoding that is not GSM 7bit is ignored
+	s.GSMCoding = 0xF5
+	if i := s.AutoCoding(text); i.DataCoding != CodingLatin1 {
+		t.Fatal(i.DataCoding)
+	}
+}
+
+func TestUnrepresentable(t *testing.T) {
+	for _, tt := range []struct {
+		dc   DataCoding
+		gsm  bool
+		text string
+	}{
+		{CodingDefault, true, "a😀"},
+		{CodingDefault, false, "aあ"},
+		{CodingIA5, false, "é"},
+		{CodingCyrillic, false, "あ"},
+		{0xF1, false, "a😀"},
+	} {
+		p := &SubmitSM{}
+		p.DataCoding = tt.dc
+		p.ShortMessage.Text = tt.text
+		if _, e := p.marshalText(0x34, tt.gsm); !errors.Is(e, ErrUnrepresentable) {
+			t.Errorf("%s %q: %v", tt.dc, tt.text, e)
+		}
+		m := &SubmitMultiSM{}
+		m.DataCoding = tt.dc
+		m.ShortMessage.Text = tt.text
+		if _, e := m.marshalText(0x34, tt.gsm); !errors.Is(e, ErrUnrepresentable) {
+			t.Errorf("submit_multi %s %q: %v", tt.dc, tt.text, e)
+		}
+	}
+
+	p := &SubmitSM{}
+	p.DataCoding = CodingLatin1
+	p.ShortMessage.Text = strings.Repeat("あ", 300)
+	if _, e := p.marshalText(0x33, false); !errors.Is(e, ErrUnrepresentable) || !errors.Is(e, ErrMessageTooLong) {
+		t.Fatal(e)
+	}
+}
@@ -0,0 +1,181 @@
+package smpp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// Server accepts binds from ESMEs on the listener.
+type Server struct {
+	Config *Config
+	// SessionLimit is max number of binds for each system_id.
+	// Value of key "" is used for system_id that is not in the map.
+	// No limit is applied if the value is 0 or not found.
+	SessionLimit map[string]int
+
+	mu       sync.Mutex
+	listener net.Listener
+	sessions map[*Bind]net.Conn
+	peers    map[*Bind]string
+	counts   map[string]int
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// Serve accepts connections on l and serves binds until Shutdown.
+// ErrServerStarted is returned if the server is already serving other listener.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	if s.listener != nil {
+		s.mu.Unlock()
+		return ErrServerStarted
+	}
+	s.listener = l
+	if s.sessions == nil {
+		s.sessions = make(map[*Bind]net.Conn)
+		s.peers = make(map[*Bind]string)
+		s.counts = make(map[string]int)
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.listener = nil
+		s.mu.Unlock()
+	}()
+
+	for {
+		c, e := l.Accept()
+		if e != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			if ne, ok := e.(net.Error); ok && ne.Timeout() {
+				time.Sleep(time.Millisecond * 10)
+				continue
+			}
+			return e
+		}
+
+		b := &Bind{}
+		conf := *s.Config.resolve()
+		auth := conf.Authenticator
+		conf.Authenticator = func(i BindInfo, a net.Addr) StatusCode {
+			if auth != nil {
+				if stat := auth(i, a); stat != StatOK {
+					return stat
+				}
+			}
+			return s.register(b, i.PeerID)
+		}
+		b.Config = &conf
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			c.Close()
+			return ErrServerClosed
+		}
+		s.sessions[b] = c
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			b.ListenAndServe(c)
+			s.unregister(b)
+		}()
+	}
+}
+
+func (s *Server) register(b *Bind, id string) StatusCode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return StatBindFail
+	}
+	limit, ok := s.SessionLimit[id]
+	if !ok {
+		limit = s.SessionLimit[""]
+	}
+	if limit > 0 && s.counts[id] >= limit {
+		return StatAlyBnd
+	}
+	s.counts[id]++
+	s.peers[b] = id
+	return StatOK
+}
+
+func (s *Server) unregister(b *Bind) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, b)
+	if id, ok := s.peers[b]; ok {
+		delete(s.peers, b)
+		if s.counts[id]--; s.counts[id] <= 0 {
+			delete(s.counts, id)
+		}
+	}
+}
+
+// Binds returns binds that are served now.
+func (s *Server) Binds() []*Bind {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := make([]*Bind, 0, len(s.sessions))
+	for b := range s.sessions {
+		r = append(r, b)
+	}
+	return r
+}
+
+// Shutdown closes the listener, shuts down every bind gracefully and
+// waits for them to be closed until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	sessions := make(map[*Bind]net.Conn, len(s.sessions))
+	for b, c := range s.sessions {
+		sessions[b] = c
+	}
+	s.mu.Unlock()
+
+	for b, c := range sessions {
+		if b.IsActive() {
+			go b.Shutdown(ctx)
+		} else {
+			c.Close()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, c := range sessions {
+			c.Close()
+		}
+		return ctx.Err()
+	}
+}
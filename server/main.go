@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -16,7 +18,6 @@ import (
 
 	"github.com/fkgi/smpp"
 	"github.com/fkgi/smpp/dictionary"
-	"github.com/fkgi/teldata"
 )
 
 func printHelp() {
@@ -41,10 +42,6 @@ func main() {
 	lh := flag.String("i", ":8080", "HTTP local address")
 	ph := flag.String("b", "", "HTTP backend address")
 	pw := flag.String("p", "", "Password for ESME authentication")
-	st := flag.String("y", "DEBUGGER", "Type of ESME system")
-	tn := flag.Uint("o", 0, "Type of Number for ESME address")
-	np := flag.Uint("n", 0, "Numbering Plan Indicator for ESME address")
-	ar := flag.String("a", "", "UNIX Regular Expression notation of ESME address")
 	cr := flag.String("c", "", "TLS crt file")
 	ky := flag.String("k", "", "TLS key file")
 	dict := flag.String("d", "dictionary.xml", "SMPP dictionary file `path`.")
@@ -85,13 +82,16 @@ func main() {
 	}
 	smpp.ID = *id
 
-	bind := smpp.Bind{}
-	bind.BindType = smpp.NilBind
-	bind.Password = *pw
-	bind.SystemType = *st
-	bind.TypeOfNumber = teldata.NatureOfAddress(*tn)
-	bind.NumberingPlan = teldata.NumberingPlan(*np)
-	bind.AddressRange = *ar
+	if *pw != "" {
+		smpp.Authenticator = func(i smpp.BindInfo, _ net.Addr) smpp.StatusCode {
+			if i.Password != *pw {
+				return smpp.StatInvPaswd
+			}
+			return smpp.StatOK
+		}
+	}
+
+	srv := &smpp.Server{}
 
 	log.Println("[INFO]", "booting Round-Robin diagnostic/debug subsystem for SMPP...")
 	log.Println("[INFO]", "running as SMSC\n| system ID:", *id)
@@ -103,17 +103,24 @@ func main() {
 		dictionary.Backend = ""
 	} else {
 		log.Println("[INFO]", "HTTP backend:", dictionary.Backend)
-		smpp.RequestHandler = dictionary.HandleSMPP
+		smpp.AsyncHandler = dictionary.HandleSMPPAsync
 	}
 
+	handle := func(w http.ResponseWriter, r *http.Request, req smpp.PDU) {
+		p := &smpp.Pool{}
+		for _, b := range srv.Binds() {
+			p.Add(b, 1)
+		}
+		dictionary.HandleHTTP(w, r, req, p)
+	}
 	http.HandleFunc("/smppmsg/v1/data", func(w http.ResponseWriter, r *http.Request) {
-		dictionary.HandleHTTP(w, r, &smpp.DataSM{}, &bind)
+		handle(w, r, &smpp.DataSM{})
 	})
 	http.HandleFunc("/smppmsg/v1/deliver", func(w http.ResponseWriter, r *http.Request) {
-		dictionary.HandleHTTP(w, r, &smpp.DeliverSM{}, &bind)
+		handle(w, r, &smpp.DeliverSM{})
 	})
 	http.HandleFunc("/smppmsg/v1/submit", func(w http.ResponseWriter, r *http.Request) {
-		dictionary.HandleHTTP(w, r, &smpp.SubmitSM{}, &bind)
+		handle(w, r, &smpp.SubmitSM{})
 	})
 
 	log.Println("[INFO]", "listening HTTP...\n| local port:", *lh)
@@ -124,14 +131,18 @@ func main() {
 		}
 	}()
 
-	close := func() {
+	closed := make(chan struct{})
+	shutdown := func() {
+		defer close(closed)
 		sigc := make(chan os.Signal, 1)
 		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 		if call := <-sigc; call != nil {
-			log.Println("[INFO]", "closing bind")
-			bind.Close()
-			time.Sleep(time.Second * 5)
-			os.Exit(0)
+			log.Println("[INFO]", "closing binds")
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			if e := srv.Shutdown(ctx); e != nil {
+				log.Println("[WARN]", "failed to close binds:", e)
+			}
 		}
 	}
 
@@ -155,12 +166,10 @@ func main() {
 		log.Fatalln(e)
 	}
 
-	c, e := l.Accept()
-	if e != nil {
-		log.Fatalln(e)
+	go shutdown()
+	e = srv.Serve(l)
+	if errors.Is(e, smpp.ErrServerClosed) {
+		<-closed
 	}
-	l.Close()
-
-	go close()
-	log.Println("[INFO]", "closed, error=", bind.ListenAndServe(c))
+	log.Println("[INFO]", "closed, error=", e)
 }
@@ -0,0 +1,155 @@
+package smpp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServerShutdown(t *testing.T) {
+	l := newPipeListener()
+	srv := &Server{Config: testConfig(okHandler)}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(l) }()
+
+	esmes := []*Bind{}
+	for i := 0; i < 4; i++ {
+		c, e := l.dial()
+		if e != nil {
+			t.Fatal(e)
+		}
+		b := &Bind{BindInfo: BindInfo{BindType: TRxBind}, Config: testConfig(okHandler)}
+		go b.DialAndServe(c)
+		esmes = append(esmes, b)
+	}
+	waitActive(t, esmes...)
+	for i := 0; len(srv.Binds()) != len(esmes); i++ {
+		if i == 200 {
+			t.Fatal("binds are not registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	waitActive(t, srv.Binds()...)
+
+	// send and read the binds while shutting down
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for _, b := range esmes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				b.Send(&SubmitSM{})
+				for _, s := range srv.Binds() {
+					s.IsActive()
+				}
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if e := srv.Shutdown(ctx); e != nil {
+		t.Fatal(e)
+	}
+	close(stop)
+	wg.Wait()
+
+	if e := <-served; e != ErrServerClosed {
+		t.Fatal(e)
+	}
+	waitClosed(t, esmes...)
+	if n := len(srv.Binds()); n != 0 {
+		t.Fatal(n, "binds are left")
+	}
+}
+
+func TestServerServeTwice(t *testing.T) {
+	l1, l2 := newPipeListener(), newPipeListener()
+	srv := &Server{Config: testConfig(okHandler)}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(l1) }()
+	if _, e := l1.dial(); e != nil {
+		t.Fatal(e)
+	}
+	if e := srv.Serve(l2); e != ErrServerStarted {
+		t.Fatal(e)
+	}
+	srv.Shutdown(context.Background())
+	if e := <-served; e != ErrServerClosed {
+		t.Fatal(e)
+	}
+	if e := srv.Serve(l2); e != ErrServerClosed {
+		t.Fatal(e)
+	}
+}
+
+func TestServerSessionLimit(t *testing.T) {
+	l := newPipeListener()
+	srv := &Server{Config: testConfig(okHandler),
+		SessionLimit: map[string]int{"esme1": 1, "": 2}}
+	go srv.Serve(l)
+	defer srv.Shutdown(context.Background())
+
+	bind := func(id string) (*Bind, chan error) {
+		c, e := l.dial()
+		if e != nil {
+			t.Fatal(e)
+		}
+		conf := testConfig(okHandler)
+		conf.ID = id
+		b := &Bind{BindInfo: BindInfo{BindType: TRxBind}, Config: conf}
+		err := make(chan error, 1)
+		go func() { err <- b.DialAndServe(c) }()
+		return b, err
+	}
+	rejected := func(err chan error) {
+		select {
+		case e := <-err:
+			if !errors.Is(e, StatusError{Status: StatAlyBnd}) {
+				t.Fatal(e)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("bind is not rejected")
+		}
+	}
+
+	b1, _ := bind("esme1")
+	waitActive(t, b1)
+	_, err := bind("esme1")
+	rejected(err)
+
+	// default limit is applied to each system_id
+	b2, _ := bind("esme2")
+	b3, _ := bind("esme2")
+	b4, _ := bind("esme3")
+	waitActive(t, b2, b3, b4)
+	_, err = bind("esme2")
+	rejected(err)
+
+	// unbound session is released
+	b1.Close()
+	waitClosed(t, b1)
+	for i := 0; ; i++ {
+		b, err := bind("esme1")
+		select {
+		case <-err:
+			if i == 20 {
+				t.Fatal("session is not released")
+			}
+			time.Sleep(10 * time.Millisecond)
+			continue
+		case <-time.After(50 * time.Millisecond):
+		}
+		waitActive(t, b)
+		break
+	}
+}
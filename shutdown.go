@@ -0,0 +1,82 @@
+package smpp
+
+import (
+	"context"
+	"fmt"
+)
+
+// Request identifies request PDU that is waiting for the answer.
+type Request struct {
+	Direction Direction
+	CommandID CommandID
+	Sequence  uint32
+}
+
+func (r Request) String() string {
+	return fmt.Sprintf("%s %s (seq:%d)", r.Direction, r.CommandID, r.Sequence)
+}
+
+// track registers pending request, it returns false if the bind is shutting down.
+func (b *Bind) track(r Request) bool {
+	b.pmu.Lock()
+	defer b.pmu.Unlock()
+	if b.closing || b.pending == nil {
+		return false
+	}
+	b.pending[r] = struct{}{}
+	return true
+}
+
+// isClosing returns true if Shutdown is started.
+func (b *Bind) isClosing() bool {
+	b.pmu.Lock()
+	defer b.pmu.Unlock()
+	return b.closing
+}
+
+func (b *Bind) untrack(r Request) {
+	b.pmu.Lock()
+	defer b.pmu.Unlock()
+	delete(b.pending, r)
+	if b.closing && len(b.pending) == 0 && b.drained != nil {
+		close(b.drained)
+		b.drained = nil
+	}
+}
+
+// Shutdown closes the bind gracefully.
+// New requests in both direction are rejected, then Shutdown waits
+// answers of pending requests until ctx is done, and sends unbind.
+// Requests that are not answered before ctx is done are returned.
+func (b *Bind) Shutdown(ctx context.Context) []Request {
+	if !b.IsActive() {
+		return nil
+	}
+
+	b.pmu.Lock()
+	b.closing = true
+	drained := make(chan struct{})
+	if len(b.pending) == 0 {
+		close(drained)
+	} else {
+		b.drained = drained
+	}
+	b.pmu.Unlock()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+	case <-b.done:
+	}
+
+	b.pmu.Lock()
+	abandoned := make([]Request, 0, len(b.pending))
+	for r := range b.pending {
+		abandoned = append(abandoned, r)
+	}
+	b.drained = nil
+	b.pmu.Unlock()
+
+	b.Close()
+	return abandoned
+}
@@ -0,0 +1,87 @@
+package smpp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShutdownDrain(t *testing.T) {
+	release := make(chan struct{})
+	smsc := &Bind{Config: testConfig(func(i BindInfo, p PDU) (StatusCode, PDU) {
+		<-release
+		return okHandler(i, p)
+	})}
+	esme := &Bind{Config: testConfig(okHandler)}
+	pipe(t, smsc, esme)
+
+	res := make(chan error, 1)
+	go func() {
+		_, _, e := esme.Send(&SubmitSM{})
+		res <- e
+	}()
+	for esme.InFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan []Request, 1)
+	go func() { done <- esme.Shutdown(context.Background()) }()
+	for !esme.isClosing() {
+		time.Sleep(time.Millisecond)
+	}
+	// new requests are rejected while shutting down
+	if _, _, e := esme.Send(&SubmitSM{}); e != ErrClosed {
+		t.Fatal(e)
+	}
+	select {
+	case <-done:
+		t.Fatal("shutdown before the answer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if e := <-res; e != nil {
+		t.Fatal("pending request failed:", e)
+	}
+	if r := <-done; len(r) != 0 {
+		t.Fatal(r)
+	}
+	waitClosed(t, smsc, esme)
+}
+
+func TestShutdownAbandon(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	smsc := &Bind{Config: testConfig(func(i BindInfo, p PDU) (StatusCode, PDU) {
+		<-release
+		return okHandler(i, p)
+	})}
+	esme := &Bind{Config: testConfig(okHandler)}
+	pipe(t, smsc, esme)
+
+	go esme.Send(&SubmitSM{})
+	for n := 0; n == 0; {
+		time.Sleep(time.Millisecond)
+		smsc.pmu.Lock()
+		n = len(smsc.pending)
+		smsc.pmu.Unlock()
+	}
+
+	// SMSC rejects new requests while shutting down
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan []Request, 1)
+	go func() { done <- smsc.Shutdown(ctx) }()
+	for !smsc.isClosing() {
+		time.Sleep(time.Millisecond)
+	}
+	if s, _, e := esme.Send(&SubmitSM{}); e != nil || s != StatInvBndSts {
+		t.Fatal(s, e)
+	}
+
+	r := <-done
+	if len(r) != 1 || r[0].Direction != Rx || r[0].CommandID != SubmitSm {
+		t.Fatal(r)
+	}
+	waitClosed(t, smsc, esme)
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"strings"
 
 	"github.com/fkgi/teldata"
@@ -26,7 +28,7 @@ type smPDU struct {
 	ValidityPeriod       string             `json:"validity_period,omitempty"`
 	RegisteredDelivery   registeredDelivery `json:"registered_delivery"`
 	ReplaceIfPresentFlag bool               `json:"replace_if_present_flag,omitempty"`
-	DataCoding           byte               `json:"data_coding"`
+	DataCoding           DataCoding         `json:"data_coding"`
 	SmDefaultMsgId       byte               `json:"sm_default_sm_id,omitempty"`
 	// SmLength            byte
 	ShortMessage UserData `json:"short_message,omitempty"`
@@ -59,7 +61,19 @@ func (d *smPDU) String() string {
 	return buf.String()
 }
 
+// Marshal returns the PDU, user data is truncated if it is too long for v.
 func (d *smPDU) Marshal(v byte) []byte {
+	b, _ := d.marshalText(v, DefaultAlphabetIsGSM)
+	return b
+}
+
+// marshalText writes user data to message_payload if it is longer than 254 octets
+// or message_payload is in Param. message_payload in Param is sent as is
+// if ShortMessage is empty.
+// ErrMessageTooLong is returned with truncated user data if v is older than 3.4.
+// ErrUnrepresentable is returned with user data that has replaced characters
+// if the text is not representable in the data_coding.
+func (d *smPDU) marshalText(v byte, gsm bool) (_ []byte, e error) {
 	w := new(bytes.Buffer)
 	writeCString([]byte(d.SvcType), w)
 	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
@@ -74,20 +88,39 @@ func (d *smPDU) Marshal(v byte) []byte {
 	writeCString([]byte(d.ValidityPeriod), w)
 	d.RegisteredDelivery.writeTo(w)
 	writeBool(d.ReplaceIfPresentFlag, w)
-	w.WriteByte(d.DataCoding)
+	d.DataCoding.writeTo(w)
 	w.WriteByte(d.SmDefaultMsgId)
 
-	ud := d.ShortMessage.marshal(d.DataCoding)
+	p := d.Param
+	ud, e := d.ShortMessage.marshal(d.DataCoding, gsm)
+	_, payload := d.Param[messagePayload]
+	if v < 0x34 {
+		if len(ud) > 254 {
+			ud = ud[:254]
+			e = errors.Join(e, ErrMessageTooLong)
+		}
+	} else if len(ud) > 254 || payload && len(ud) != 0 {
+		p = maps.Clone(d.Param)
+		if p == nil {
+			p = OptionalParameters{}
+		}
+		p[messagePayload] = ud
+		ud = []byte{}
+	}
 	w.WriteByte(byte(len(ud)))
 	w.Write(ud)
 
 	if v >= 0x34 {
-		d.Param.writeTo(w)
+		p.writeTo(w)
 	}
-	return w.Bytes()
+	return w.Bytes(), e
+}
+
+func (d *smPDU) Unmarshal(data []byte) error {
+	return d.unmarshalText(data, DefaultAlphabetIsGSM)
 }
 
-func (d *smPDU) Unmarshal(data []byte) (e error) {
+func (d *smPDU) unmarshalText(data []byte, gsm bool) (e error) {
 	buf := bytes.NewBuffer(data)
 	var l byte
 	if d.SvcType, e = readCString(buf); e != nil {
@@ -100,14 +133,19 @@ func (d *smPDU) Unmarshal(data []byte) (e error) {
 	} else if d.ValidityPeriod, e = readCString(buf); e != nil {
 	} else if e = d.RegisteredDelivery.readFrom(buf); e != nil {
 	} else if d.ReplaceIfPresentFlag, e = readBool(buf); e != nil {
-	} else if d.DataCoding, e = buf.ReadByte(); e != nil {
+	} else if e = d.DataCoding.readFrom(buf); e != nil {
 	} else if d.SmDefaultMsgId, e = buf.ReadByte(); e != nil {
 	} else if l, e = buf.ReadByte(); e == nil {
 		ud := make([]byte, int(l))
-		if _, e = buf.Read(ud); e == nil {
-			d.ShortMessage.unmarshal(ud, d.DataCoding, d.EsmClass.UDHI)
+		if _, e = io.ReadFull(buf, ud); e == nil {
 			d.Param = OptionalParameters{}
 			e = d.Param.readFrom(buf)
+			if p, ok := d.Param[messagePayload]; ok && l == 0 {
+				ud = p
+			}
+			if ue := d.ShortMessage.unmarshal(ud, d.DataCoding, d.EsmClass.UDHI, gsm); e == nil {
+				e = ue
+			}
 		}
 	}
 	return
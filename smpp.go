@@ -7,11 +7,12 @@ import (
 )
 
 var (
-	ID = ""
-	// WhiteList []BindInfo
+	ID        = ""
 	KeepAlive = time.Second * 30
 	Expire    = time.Second * 10
 	Indent    = "|"
+
+	InterfaceVersion byte = 0x34
 )
 
 type CommandID uint32
@@ -45,6 +46,13 @@ const (
 	DataSm              CommandID = 0x00000103
 	DataSmResp          CommandID = 0x80000103
 
+	BroadcastSm           CommandID = 0x00000111
+	BroadcastSmResp       CommandID = 0x80000111
+	QueryBroadcastSm      CommandID = 0x00000112
+	QueryBroadcastSmResp  CommandID = 0x80000112
+	CancelBroadcastSm     CommandID = 0x00000113
+	CancelBroadcastSmResp CommandID = 0x80000113
+
 	closeConnection CommandID = 0xf0000001
 	internalFailure CommandID = 0xf0000000
 )
@@ -109,6 +117,18 @@ func (c CommandID) String() string {
 		return "data_sm"
 	case DataSmResp:
 		return "data_sm_resp"
+	case BroadcastSm:
+		return "broadcast_sm"
+	case BroadcastSmResp:
+		return "broadcast_sm_resp"
+	case QueryBroadcastSm:
+		return "query_broadcast_sm"
+	case QueryBroadcastSmResp:
+		return "query_broadcast_sm_resp"
+	case CancelBroadcastSm:
+		return "cancel_broadcast_sm"
+	case CancelBroadcastSmResp:
+		return "cancel_broadcast_sm_resp"
 	}
 	return "reserved"
 }
@@ -0,0 +1,302 @@
+package smpp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/fkgi/teldata"
+)
+
+type destFlag byte
+
+const (
+	SMEAddress       destFlag = 0x01
+	DistributionList destFlag = 0x02
+)
+
+func (f destFlag) String() string {
+	switch f {
+	case SMEAddress:
+		return "sme_address"
+	case DistributionList:
+		return "distribution_list"
+	}
+	return "unknown"
+}
+
+func (f destFlag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+func (f *destFlag) UnmarshalJSON(b []byte) (e error) {
+	s := ""
+	if e = json.Unmarshal(b, &s); e != nil {
+		return
+	}
+	switch s {
+	case "sme_address":
+		*f = SMEAddress
+	case "distribution_list":
+		*f = DistributionList
+	default:
+		e = errors.New("invalid Destination Flag: " + s)
+	}
+	return
+}
+
+type DestAddress struct {
+	Flag    destFlag                `json:"dest_flag"`
+	DstTON  teldata.NatureOfAddress `json:"dst_ton,omitempty"`
+	DstNPI  teldata.NumberingPlan   `json:"dst_npi,omitempty"`
+	DstAddr string                  `json:"dst_addr,omitempty"`
+	DLName  string                  `json:"dl_name,omitempty"`
+}
+
+func (a DestAddress) String() string {
+	if a.Flag == DistributionList {
+		return fmt.Sprintf("dl_name=%s", a.DLName)
+	}
+	return fmt.Sprintf("%s(ton=%d, npi=%d)", a.DstAddr, a.DstTON, a.DstNPI)
+}
+
+func (a DestAddress) writeTo(w *bytes.Buffer) {
+	if a.Flag == DistributionList {
+		w.WriteByte(byte(DistributionList))
+		writeCString([]byte(a.DLName), w)
+	} else {
+		w.WriteByte(byte(SMEAddress))
+		writeAddr(a.DstTON, a.DstNPI, a.DstAddr, w)
+	}
+}
+
+func (a *DestAddress) readFrom(buf *bytes.Buffer) (e error) {
+	var f byte
+	if f, e = buf.ReadByte(); e != nil {
+		return
+	}
+	switch a.Flag = destFlag(f); a.Flag {
+	case SMEAddress:
+		a.DstTON, a.DstNPI, a.DstAddr, e = readAddr(buf)
+	case DistributionList:
+		a.DLName, e = readCString(buf)
+	default:
+		e = fmt.Errorf("invalid dest_flag: %#x", f)
+	}
+	return
+}
+
+type SubmitMultiSM struct {
+	SvcType  string                  `json:"svc_type,omitempty"`
+	SrcTON   teldata.NatureOfAddress `json:"src_ton,omitempty"`
+	SrcNPI   teldata.NumberingPlan   `json:"src_npi,omitempty"`
+	SrcAddr  string                  `json:"src_addr,omitempty"`
+	DstAddrs []DestAddress           `json:"dst_addrs"`
+	EsmClass esmClass                `json:"esm_class"`
+
+	ProtocolId           byte               `json:"protocol_id"`
+	PriorityFlag         byte               `json:"priority_flag"`
+	ScheduleDeliveryTime string             `json:"schedule_delivery_time,omitempty"`
+	ValidityPeriod       string             `json:"validity_period,omitempty"`
+	RegisteredDelivery   registeredDelivery `json:"registered_delivery"`
+	ReplaceIfPresentFlag bool               `json:"replace_if_present_flag,omitempty"`
+	DataCoding           DataCoding         `json:"data_coding"`
+	SmDefaultMsgId       byte               `json:"sm_default_sm_id,omitempty"`
+	// SmLength            byte
+	ShortMessage UserData `json:"short_message,omitempty"`
+
+	Param OptionalParameters `json:"options,omitempty"`
+}
+
+func (d *SubmitMultiSM) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "service_type           :", d.SvcType)
+	fmt.Fprintln(buf, Indent, "source_addr_ton        :", d.SrcTON)
+	fmt.Fprintln(buf, Indent, "source_addr_npi        :", d.SrcNPI)
+	fmt.Fprintln(buf, Indent, "source_addr            :", d.SrcAddr)
+	fmt.Fprintln(buf, Indent, "number_of_dests        :", len(d.DstAddrs))
+	for _, a := range d.DstAddrs {
+		fmt.Fprintln(buf, Indent, Indent, "dest_address:", a)
+	}
+	fmt.Fprintln(buf, Indent, "esm_class              :", d.EsmClass)
+	fmt.Fprintln(buf, Indent, "protocol_id            :", d.ProtocolId)
+	fmt.Fprintln(buf, Indent, "priority_flag          :", d.PriorityFlag)
+	fmt.Fprintln(buf, Indent, "schedule_delivery_time :", d.ScheduleDeliveryTime)
+	fmt.Fprintln(buf, Indent, "validity_period        :", d.ValidityPeriod)
+	fmt.Fprintln(buf, Indent, "registered_delivery    :", d.RegisteredDelivery)
+	fmt.Fprintln(buf, Indent, "replace_if_present_flag:", d.ReplaceIfPresentFlag)
+	fmt.Fprintln(buf, Indent, "data_coding            :", d.DataCoding)
+	fmt.Fprintln(buf, Indent, "sm_default_msg_id      :", d.SmDefaultMsgId)
+	fmt.Fprintln(buf, Indent, "short_message          :", d.ShortMessage)
+	fmt.Fprint(buf, d.Param)
+	return buf.String()
+}
+
+func (*SubmitMultiSM) CommandID() CommandID { return SubmitMulti }
+
+func (d *SubmitMultiSM) Marshal(v byte) []byte {
+	b, _ := d.marshalText(v, DefaultAlphabetIsGSM)
+	return b
+}
+
+// marshalText writes user data to message_payload and returns error in the same way as submit_sm.
+func (d *SubmitMultiSM) marshalText(v byte, gsm bool) (_ []byte, e error) {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.SvcType), w)
+	writeAddr(d.SrcTON, d.SrcNPI, d.SrcAddr, w)
+	w.WriteByte(byte(len(d.DstAddrs)))
+	for _, a := range d.DstAddrs {
+		a.writeTo(w)
+	}
+	if len(d.ShortMessage.UDH) != 0 {
+		d.EsmClass.UDHI = true
+	}
+	d.EsmClass.writeTo(w)
+	w.WriteByte(d.ProtocolId)
+	w.WriteByte(d.PriorityFlag)
+	writeCString([]byte(d.ScheduleDeliveryTime), w)
+	writeCString([]byte(d.ValidityPeriod), w)
+	d.RegisteredDelivery.writeTo(w)
+	writeBool(d.ReplaceIfPresentFlag, w)
+	d.DataCoding.writeTo(w)
+	w.WriteByte(d.SmDefaultMsgId)
+
+	p := d.Param
+	ud, e := d.ShortMessage.marshal(d.DataCoding, gsm)
+	_, payload := d.Param[messagePayload]
+	if v < 0x34 {
+		if len(ud) > 254 {
+			ud = ud[:254]
+			e = errors.Join(e, ErrMessageTooLong)
+		}
+	} else if len(ud) > 254 || payload && len(ud) != 0 {
+		p = maps.Clone(d.Param)
+		if p == nil {
+			p = OptionalParameters{}
+		}
+		p[messagePayload] = ud
+		ud = []byte{}
+	}
+	w.WriteByte(byte(len(ud)))
+	w.Write(ud)
+
+	if v >= 0x34 {
+		p.writeTo(w)
+	}
+	return w.Bytes(), e
+}
+
+func (d *SubmitMultiSM) Unmarshal(data []byte) error {
+	return d.unmarshalText(data, DefaultAlphabetIsGSM)
+}
+
+func (d *SubmitMultiSM) unmarshalText(data []byte, gsm bool) (e error) {
+	buf := bytes.NewBuffer(data)
+	var n, l byte
+	if d.SvcType, e = readCString(buf); e != nil {
+		return
+	} else if d.SrcTON, d.SrcNPI, d.SrcAddr, e = readAddr(buf); e != nil {
+		return
+	} else if n, e = buf.ReadByte(); e != nil {
+		return
+	}
+	d.DstAddrs = make([]DestAddress, int(n))
+	for i := range d.DstAddrs {
+		if e = d.DstAddrs[i].readFrom(buf); e != nil {
+			return
+		}
+	}
+
+	if e = d.EsmClass.readFrom(buf); e != nil {
+	} else if d.ProtocolId, e = buf.ReadByte(); e != nil {
+	} else if d.PriorityFlag, e = buf.ReadByte(); e != nil {
+	} else if d.ScheduleDeliveryTime, e = readCString(buf); e != nil {
+	} else if d.ValidityPeriod, e = readCString(buf); e != nil {
+	} else if e = d.RegisteredDelivery.readFrom(buf); e != nil {
+	} else if d.ReplaceIfPresentFlag, e = readBool(buf); e != nil {
+	} else if e = d.DataCoding.readFrom(buf); e != nil {
+	} else if d.SmDefaultMsgId, e = buf.ReadByte(); e != nil {
+	} else if l, e = buf.ReadByte(); e == nil {
+		ud := make([]byte, int(l))
+		if _, e = io.ReadFull(buf, ud); e == nil {
+			d.Param = OptionalParameters{}
+			e = d.Param.readFrom(buf)
+			if p, ok := d.Param[messagePayload]; ok && l == 0 {
+				ud = p
+			}
+			if ue := d.ShortMessage.unmarshal(ud, d.DataCoding, d.EsmClass.UDHI, gsm); e == nil {
+				e = ue
+			}
+		}
+	}
+	return
+}
+
+type UnsuccessSME struct {
+	DstTON  teldata.NatureOfAddress `json:"dst_ton"`
+	DstNPI  teldata.NumberingPlan   `json:"dst_npi"`
+	DstAddr string                  `json:"dst_addr"`
+	Status  StatusCode              `json:"error_status_code"`
+}
+
+func (u UnsuccessSME) String() string {
+	return fmt.Sprintf("%s(ton=%d, npi=%d) %s", u.DstAddr, u.DstTON, u.DstNPI, u.Status)
+}
+
+type SubmitMultiSM_resp struct {
+	MessageID string         `json:"id,omitempty"`
+	Unsuccess []UnsuccessSME `json:"unsuccess_sme,omitempty"`
+}
+
+func (d *SubmitMultiSM_resp) String() string {
+	buf := new(strings.Builder)
+	fmt.Fprintln(buf)
+	fmt.Fprintln(buf, Indent, "message_id  :", d.MessageID)
+	fmt.Fprint(buf, Indent, " no_unsuccess: ", len(d.Unsuccess))
+	for _, u := range d.Unsuccess {
+		fmt.Fprintf(buf, "\n%s %s unsuccess_sme: %s", Indent, Indent, u)
+	}
+	return buf.String()
+}
+
+func (*SubmitMultiSM_resp) CommandID() CommandID { return SubmitMultiResp }
+
+func (d *SubmitMultiSM_resp) Marshal(byte) []byte {
+	w := new(bytes.Buffer)
+	writeCString([]byte(d.MessageID), w)
+	w.WriteByte(byte(len(d.Unsuccess)))
+	for _, u := range d.Unsuccess {
+		writeAddr(u.DstTON, u.DstNPI, u.DstAddr, w)
+		binary.Write(w, binary.BigEndian, u.Status)
+	}
+	return w.Bytes()
+}
+
+func (d *SubmitMultiSM_resp) Unmarshal(data []byte) (e error) {
+	if len(data) == 0 {
+		return
+	}
+	buf := bytes.NewBuffer(data)
+	var n byte
+	if d.MessageID, e = readCString(buf); e != nil {
+		return
+	} else if n, e = buf.ReadByte(); e != nil {
+		return
+	}
+	d.Unsuccess = make([]UnsuccessSME, int(n))
+	for i := range d.Unsuccess {
+		u := &d.Unsuccess[i]
+		if u.DstTON, u.DstNPI, u.DstAddr, e = readAddr(buf); e != nil {
+			return
+		} else if e = binary.Read(buf, binary.BigEndian, &u.Status); e != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,171 @@
+package smpp
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit is token bucket that allows Rate requests per second.
+// Burst is size of the bucket, ceil of Rate is used if it is 0.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	current float64
+	until   time.Time
+}
+
+// Allow takes a token from the bucket. Nil RateLimit allows every request.
+func (l *RateLimit) Allow() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reserve(time.Now()) == 0
+}
+
+// Wait waits a token from the bucket until ctx is done.
+func (l *RateLimit) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		l.mu.Lock()
+		d := l.reserve(time.Now())
+		l.mu.Unlock()
+		if d == 0 {
+			return nil
+		}
+
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctxError(ctx)
+		case <-t.C:
+		}
+	}
+}
+
+// reserve takes a token and returns 0,
+// or returns duration to wait for the next token.
+func (l *RateLimit) reserve(now time.Time) time.Duration {
+	if now.Before(l.until) {
+		return l.until.Sub(now)
+	}
+	rate := l.Rate
+	if l.current > 0 {
+		rate = l.current
+	}
+	if rate <= 0 {
+		return 0
+	}
+
+	burst := float64(l.Burst)
+	if burst <= 0 {
+		burst = math.Ceil(l.Rate)
+	}
+	if l.last.IsZero() {
+		l.tokens = burst
+	} else {
+		l.tokens = math.Min(burst, l.tokens+now.Sub(l.last).Seconds()*rate)
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return time.Duration((1 - l.tokens) / rate * float64(time.Second))
+	}
+	l.tokens--
+	return 0
+}
+
+// throttled halves current rate and pauses the bucket for d.
+func (l *RateLimit) throttled(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.until = time.Now().Add(d)
+	l.tokens = 0
+	if l.Rate <= 0 {
+		return
+	}
+	if l.current == 0 {
+		l.current = l.Rate
+	}
+	l.current = math.Max(l.current/2, math.Min(1, l.Rate))
+}
+
+// recovered increases current rate to Rate gradually.
+func (l *RateLimit) recovered() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.current == 0 {
+		return
+	}
+	if l.current += l.Rate / 20; l.current >= l.Rate {
+		l.current = 0
+	}
+}
+
+// idle returns true if the bucket is refilled and is same as new one.
+func (l *RateLimit) idle(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.last.IsZero() {
+		return true
+	}
+	if now.Before(l.until) || l.current > 0 {
+		return false
+	}
+	burst := float64(l.Burst)
+	if burst <= 0 {
+		burst = math.Ceil(l.Rate)
+	}
+	return l.tokens+now.Sub(l.last).Seconds()*l.Rate >= burst
+}
+
+// Throttle is RateLimit for each system_id, it is shared by binds.
+// RateLimit of the system_id that has been idle until the bucket is refilled
+// is evicted.
+type Throttle struct {
+	Rate  float64
+	Burst int
+
+	mu     sync.Mutex
+	limits map[string]*RateLimit
+	sweep  time.Time
+}
+
+func (t *Throttle) Allow(id string) bool {
+	if t == nil || t.Rate <= 0 {
+		return true
+	}
+	t.mu.Lock()
+	if t.limits == nil {
+		t.limits = make(map[string]*RateLimit)
+	}
+	l, ok := t.limits[id]
+	if !ok {
+		l = &RateLimit{Rate: t.Rate, Burst: t.Burst}
+		t.limits[id] = l
+	}
+	if now := time.Now(); now.After(t.sweep) {
+		for k, v := range t.limits {
+			if k != id && v.idle(now) {
+				delete(t.limits, k)
+			}
+		}
+		burst := float64(t.Burst)
+		if burst <= 0 {
+			burst = math.Ceil(t.Rate)
+		}
+		t.sweep = now.Add(time.Duration(burst / t.Rate * float64(time.Second)))
+	}
+	t.mu.Unlock()
+	return l.Allow()
+}
@@ -0,0 +1,161 @@
+package smpp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	var nilLimit *RateLimit
+	if !nilLimit.Allow() || nilLimit.Wait(context.Background()) != nil {
+		t.Fatal("nil RateLimit limits request")
+	}
+
+	l := &RateLimit{Rate: 100, Burst: 2}
+	if !l.Allow() || !l.Allow() {
+		t.Fatal("burst is not allowed")
+	}
+	if l.Allow() {
+		t.Fatal("not limited")
+	}
+	now := time.Now()
+	if e := l.Wait(context.Background()); e != nil {
+		t.Fatal(e)
+	}
+	if d := time.Since(now); d < 5*time.Millisecond || d > 100*time.Millisecond {
+		t.Fatal("waited", d)
+	}
+
+	l = &RateLimit{Rate: 1}
+	l.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if e := l.Wait(ctx); e != ErrTimeout {
+		t.Fatal(e)
+	}
+}
+
+func TestInboundThrottle(t *testing.T) {
+	conf := testConfig(okHandler)
+	conf.InboundRate = 1
+	conf.InboundBurst = 2
+	smsc := &Bind{Config: conf}
+	esme := &Bind{Config: testConfig(okHandler)}
+	pipe(t, smsc, esme)
+
+	for i, want := range []StatusCode{StatOK, StatOK, StatThrottled} {
+		if s, _, e := esme.Send(&SubmitSM{}); e != nil || s != want {
+			t.Fatal(i, s, e)
+		}
+	}
+
+	// SystemThrottle is shared by the binds of same system_id
+	th := &Throttle{Rate: 1, Burst: 1}
+	conf1, conf2 := testConfig(okHandler), testConfig(okHandler)
+	conf1.SystemThrottle, conf2.SystemThrottle = th, th
+	esme1, esme2 := &Bind{Config: testConfig(okHandler)}, &Bind{Config: testConfig(okHandler)}
+	pipe(t, &Bind{Config: conf1}, esme1)
+	pipe(t, &Bind{Config: conf2}, esme2)
+
+	if s, _, e := esme1.Send(&SubmitSM{}); e != nil || s != StatOK {
+		t.Fatal(s, e)
+	}
+	if s, _, e := esme2.Send(&SubmitSM{}); e != nil || s != StatThrottled {
+		t.Fatal(s, e)
+	}
+}
+
+func TestThrottleEvict(t *testing.T) {
+	th := &Throttle{Rate: 100, Burst: 1}
+	for i := 0; i < 100; i++ {
+		th.Allow(fmt.Sprint("esme", i))
+	}
+	if len(th.limits) != 100 {
+		t.Fatal(len(th.limits))
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if th.Allow("esme0"); len(th.limits) != 1 {
+		t.Fatal(len(th.limits), "limits are left")
+	}
+	if !th.Allow("esme1") {
+		t.Fatal("evicted system_id is throttled")
+	}
+	if th.Allow("esme1") {
+		t.Fatal("not throttled")
+	}
+}
+
+func TestOutboundRate(t *testing.T) {
+	conf := testConfig(okHandler)
+	conf.OutboundRate = 50
+	conf.OutboundBurst = 1
+	esme := &Bind{Config: conf}
+	pipe(t, &Bind{Config: testConfig(okHandler)}, esme)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if s, _, e := esme.Send(&SubmitSM{}); e != nil || s != StatOK {
+			t.Fatal(s, e)
+		}
+	}
+	if d := time.Since(now); d < 35*time.Millisecond {
+		t.Fatal("not limited", d)
+	}
+}
+
+func TestAdaptiveRate(t *testing.T) {
+	var mu sync.Mutex
+	stat := StatThrottled
+	smsc := &Bind{Config: testConfig(func(i BindInfo, p PDU) (StatusCode, PDU) {
+		mu.Lock()
+		defer mu.Unlock()
+		if stat != StatOK {
+			return stat, &SubmitSM_resp{}
+		}
+		return okHandler(i, p)
+	})}
+	conf := testConfig(okHandler)
+	conf.OutboundRate = 100
+	conf.AdaptiveRate = true
+	conf.ThrottlePause = 50 * time.Millisecond
+	esme := &Bind{Config: conf}
+	pipe(t, smsc, esme)
+	current := func() float64 {
+		esme.txLimit.mu.Lock()
+		defer esme.txLimit.mu.Unlock()
+		return esme.txLimit.current
+	}
+
+	if s, _, e := esme.Send(&SubmitSM{}); e != nil || s != StatThrottled {
+		t.Fatal(s, e)
+	}
+	if c := current(); c != 50 {
+		t.Fatal("rate is not halved", c)
+	}
+	mu.Lock()
+	stat = StatOK
+	mu.Unlock()
+
+	now := time.Now()
+	if s, _, e := esme.Send(&SubmitSM{}); e != nil || s != StatOK {
+		t.Fatal(s, e)
+	}
+	if d := time.Since(now); d < 40*time.Millisecond {
+		t.Fatal("not paused", d)
+	}
+	if c := current(); c != 55 {
+		t.Fatal("rate is not increased", c)
+	}
+	for i := 0; i < 9; i++ {
+		if s, _, e := esme.Send(&SubmitSM{}); e != nil || s != StatOK {
+			t.Fatal(s, e)
+		}
+	}
+	if c := current(); c != 0 {
+		t.Fatal("rate is not recovered", c)
+	}
+}
@@ -4,9 +4,8 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"unicode/utf16"
-
-	"github.com/fkgi/sms"
 )
 
 var DefaultAlphabetIsGSM bool
@@ -44,61 +43,74 @@ func (u UserData) Get8bitData() ([]byte, error) {
 	return hex.DecodeString(u.Text)
 }
 
-func (u *UserData) unmarshal(ud []byte, dc byte, h bool) {
+// unmarshal decodes ud, ErrInvalidUDH is returned if UDH is longer than ud.
+func (u *UserData) unmarshal(ud []byte, dc DataCoding, h, gsm bool) error {
 	o := 0
-	l := len(ud)
-	if l == 0 {
-		return
+	if len(ud) == 0 {
+		return nil
 	}
 	if h {
-		o = int(ud[0]+1) * 8
-		l -= o / 7
-		o %= 7
-		if o != 0 {
+		l := int(ud[0]) + 1
+		if l > len(ud) {
+			return ErrInvalidUDH
+		}
+		if o = l * 8 % 7; o != 0 {
 			o = 7 - o
-			l--
 		}
 
 		u.UDH = []UserDataHdr{}
-		for buf := bytes.NewBuffer(ud[1 : ud[0]+1]); buf.Len() != 0; {
+		for buf := bytes.NewBuffer(ud[1:l]); buf.Len() != 0; {
 			k, _ := buf.ReadByte()
-			l, _ := buf.ReadByte()
-			v := make([]byte, l)
-			buf.Read(v)
+			n, e := buf.ReadByte()
+			if e != nil {
+				return ErrInvalidUDH
+			}
+			v := make([]byte, n)
+			if _, e = io.ReadFull(buf, v); e != nil {
+				return ErrInvalidUDH
+			}
 			udh := UserDataHdr{
 				Key: k,
 				Val: OctetData(v)}
 			u.UDH = append(u.UDH, udh)
 		}
-		ud = ud[ud[0]+1:]
-	}
-
-	if dc == 0x00 && !DefaultAlphabetIsGSM {
-		dc = 0x03
-	} else if 0xe0&dc == 0xc0 {
-		dc = 0x00
-	} else if 0xf4&dc == 0xf0 {
-		dc = 0x00
+		ud = ud[l:]
 	}
 
-	switch dc {
-	case 0x00:
-		s := sms.UnmarshalGSM7bitString(o, l, ud)
-		u.Text = s.String()
-	case 0x03:
-		u.Text = string(ud)
-	case 0x08:
+	switch dc = dc.charset(gsm); dc {
+	case CodingDefault:
+		lock, single := u.shiftTables()
+		u.Text = gsm7Decode(gsm7Unpack(ud, o), lock, single)
+	case CodingIA5, CodingLatin1:
+		s := make([]rune, len(ud))
+		for i, c := range ud {
+			s[i] = rune(c)
+			if dc == CodingIA5 {
+				s[i] &= 0x7f
+			}
+		}
+		u.Text = string(s)
+	case CodingUCS2:
 		s := make([]uint16, len(ud)/2)
 		for i := range s {
 			s[i] = uint16(ud[2*i])<<8 | uint16(ud[2*i+1])
 		}
 		u.Text = string(utf16.Decode(s))
-	default:
+	case CodingBinary:
 		u.Text = hex.EncodeToString(ud)
+	default:
+		if s, e := dc.encoding().NewDecoder().Bytes(ud); e == nil {
+			u.Text = string(s)
+		} else {
+			u.Text = hex.EncodeToString(ud)
+		}
 	}
+	return nil
 }
 
-func (u UserData) marshal(dc byte) []byte {
+// marshal encodes u, character that is not representable in dc
+// is replaced with ErrUnrepresentable.
+func (u UserData) marshal(dc DataCoding, gsm bool) (_ []byte, e error) {
 	w := bytes.Buffer{}
 	for _, u := range u.UDH {
 		w.WriteByte(u.Key)
@@ -113,25 +125,28 @@ func (u UserData) marshal(dc byte) []byte {
 		w.Write(d)
 	}
 
-	if dc == 0x00 && !DefaultAlphabetIsGSM {
-		dc = 0x03
-	} else if 0xe0&dc == 0xc0 {
-		dc = 0x00
-	} else if 0xf4&dc == 0xf0 {
-		dc = 0x00
-	}
-
-	switch dc {
-	case 0x00:
-		o := (len(d) * 8) % 7
+	switch dc = dc.charset(gsm); dc {
+	case CodingDefault:
+		o := 0
+		if len(d) != 0 {
+			o = ((len(d) + 1) * 8) % 7
+		}
 		if o != 0 {
 			o = 7 - o
 		}
-		s, _ := sms.StringToGSM7bit(u.Text)
-		w.Write(s.Marshal(o))
-	case 0x03:
-		w.Write([]byte(u.Text))
-	case 0x08:
+		lock, single := u.shiftTables()
+		var s []byte
+		s, e = gsm7Encode(u.Text, lock, single)
+		w.Write(gsm7Pack(s, o))
+	case CodingIA5, CodingLatin1:
+		for _, c := range u.Text {
+			if c > 0xff || dc == CodingIA5 && c > 0x7f {
+				c = '?'
+				e = ErrUnrepresentable
+			}
+			w.WriteByte(byte(c))
+		}
+	case CodingUCS2:
 		u := utf16.Encode([]rune(u.Text))
 		ud := make([]byte, len(u)*2)
 		for i, c := range u {
@@ -139,13 +154,32 @@ func (u UserData) marshal(dc byte) []byte {
 			ud[i*2+1] = byte(c & 0xff)
 		}
 		w.Write(ud)
-	default:
+	case CodingBinary:
 		ud, e := hex.DecodeString(u.Text)
 		if e != nil {
 			ud = []byte{}
 		}
 		w.Write(ud)
+	default:
+		var b []byte
+		b, e = encodeText(dc.encoding(), u.Text)
+		w.Write(b)
 	}
 
-	return w.Bytes()
+	return w.Bytes(), e
+}
+
+// shiftTables returns national language identifiers of
+// locking shift (IEI 0x25) and single shift (IEI 0x24) in UDH,
+// or 0 for default tables.
+func (u UserData) shiftTables() (lock, single byte) {
+	for _, h := range u.UDH {
+		if len(h.Val) != 1 {
+		} else if h.Key == 0x24 {
+			single = h.Val[0]
+		} else if h.Key == 0x25 {
+			lock = h.Val[0]
+		}
+	}
+	return
 }
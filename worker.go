@@ -1,82 +1,140 @@
 package smpp
 
 import (
-	"fmt"
-	"time"
+	"errors"
+	"sync/atomic"
 )
 
-const (
-	minWorkers = 128
-	maxWorkers = 65535 - minWorkers
+var (
+	// Workers is number of goroutines that handle Rx requests for each bind.
+	Workers = 32
+	// QueueSize is max number of Rx requests waiting for the workers in each bind.
+	QueueSize = 1024
+	// MaxQueued is max number of Rx requests that are waiting, being handled,
+	// or waiting the response from AsyncHandler in all binds, 0 is unlimited.
+	MaxQueued int64 = 65535
 )
 
-var sharedQ = make(chan message, minWorkers+maxWorkers)
-var activeWorkers = make(chan int, 1)
+var queued atomic.Int64
 
-func init() {
-	activeWorkers <- 0
-	worker := func() {
-		for c := 0; c < 500; {
-			if len(sharedQ) < minWorkers {
-				time.Sleep(time.Millisecond * 10)
-				c++
-				continue
-			}
-			if req, ok := <-sharedQ; !ok {
-				break
-			} else {
-				handleMsg(req)
-				c = 0
-			}
-		}
-		activeWorkers <- (<-activeWorkers - 1)
+// startWorkers starts workers that handle Rx requests in rxQ.
+// Workers stop when rxQ is closed.
+func (b *Bind) startWorkers() {
+	n := b.conf.Workers
+	if b.conf.Ordered {
+		n = 1
 	}
-	for i := 0; i < minWorkers; i++ {
-		go func() {
-			for req, ok := <-sharedQ; ok; req, ok = <-sharedQ {
-				a := <-activeWorkers
-				activeWorkers <- a
-				if len(sharedQ) > minWorkers && a < maxWorkers {
-					activeWorkers <- (<-activeWorkers + 1)
-					go worker()
-				}
-				handleMsg(req)
+	b.rxQ = make(chan message, b.conf.QueueSize)
+	for i := 0; i < n; i++ {
+		go func(q chan message) {
+			for msg := range q {
+				handleMsg(msg)
+				queued.Add(-1)
 			}
-		}()
+		}(b.rxQ)
+	}
+}
+
+// enqueue queues Rx request to the workers, returns false if the queue is full.
+func (b *Bind) enqueue(msg message) bool {
+	if n := queued.Add(1); MaxQueued > 0 && n > MaxQueued {
+		queued.Add(-1)
+		return false
+	}
+	select {
+	case b.rxQ <- msg:
+		return true
+	default:
+		queued.Add(-1)
+		return false
 	}
 }
 
 func handleMsg(msg message) {
+	b := msg.bind
 	var req, res PDU
 
+	if msg.id == AlertNotification {
+		req := &AlertNotificationPDU{}
+		if b.conf.AlertNotify != nil && b.unmarshal(req, msg.body) == nil {
+			b.conf.AlertNotify(b.BindInfo, req)
+		}
+		return
+	}
+
 	switch msg.id {
+	case QuerySm:
+		req = &QuerySM{}
+		res = &QuerySM_resp{}
 	case SubmitSm:
 		req = &SubmitSM{}
 		res = &SubmitSM_resp{}
+	case SubmitMulti:
+		req = &SubmitMultiSM{}
+		res = &SubmitMultiSM_resp{}
 	case DeliverSm:
 		req = &DeliverSM{}
 		res = &DeliverSM_resp{}
+	case ReplaceSm:
+		req = &ReplaceSM{}
+		res = &ReplaceSM_resp{}
+	case CancelSm:
+		req = &CancelSM{}
+		res = &CancelSM_resp{}
 	case DataSm:
 		req = &DataSM{}
 		res = &DataSM_resp{}
-	}
-	if req == nil {
-		panic(fmt.Sprintf("unexpected request PDU (ID:%#x)", msg.id))
+	case BroadcastSm:
+		req = &BroadcastSM{}
+		res = &BroadcastSM_resp{}
+	case QueryBroadcastSm:
+		req = &QueryBroadcastSM{}
+		res = &QueryBroadcastSM_resp{}
+	case CancelBroadcastSm:
+		req = &CancelBroadcastSM{}
+		res = &CancelBroadcastSM_resp{}
 	}
 
 	stat := StatSysErr
-	if e := req.Unmarshal(msg.body); e != nil || RequestHandler == nil {
+	if req == nil {
+		stat = StatInvCmdID
+		res = &genericNack{}
+	} else if e := b.unmarshal(req, msg.body); errors.Is(e, ErrMissingParam) {
+		stat = StatMissingOptParam
+	} else if e != nil || (b.conf.RequestHandler == nil && b.conf.AsyncHandler == nil) {
 		res = &genericNack{}
-	} else if stat, res = RequestHandler(msg.bind.BindInfo, req); res == nil {
+	} else if b.conf.AsyncHandler != nil {
+		b.conf.AsyncHandler(b.BindInfo, req, newResponder(msg, b.conf.ResponseTimeout))
+		return
+	} else if stat, res = b.conf.RequestHandler(b.BindInfo, req); res == nil {
 		// reject
+		b.untrack(Request{Direction: Rx, CommandID: msg.id, Sequence: msg.seq})
 		return
 	}
-	msg.bind.eventQ <- message{
-		id:       res.CommandID(),
+	b.respond(msg, stat, res)
+}
+
+// respond sends response of Rx request msg.
+// Response without body is sent if res is nil,
+// or StatSysErr is sent if res is not able to be marshaled.
+func (b *Bind) respond(msg message, stat StatusCode, res PDU) {
+	ans := message{
+		id:       msg.id | GenericNack,
 		stat:     stat,
 		seq:      msg.seq,
-		body:     res.Marshal(msg.bind.ver),
 		callback: dummyCallback}
+	if res == nil {
+	} else if body, e := b.marshal(res); e != nil {
+		ans.stat = StatSysErr
+	} else {
+		ans.id = res.CommandID()
+		ans.body = body
+	}
+	select {
+	case b.eventQ <- ans:
+	case <-b.done:
+	}
+	b.untrack(Request{Direction: Rx, CommandID: msg.id, Sequence: msg.seq})
 }
 
 var dummyCallback = make(chan message)